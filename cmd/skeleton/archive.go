@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generateArchive generates the project into a temporary directory exactly as generate does,
+// optionally verifies it and packs the tree into the archive.
+func generateArchive(ctx context.Context, s *generator.Settings, archive string, verify bool) (*generator.Summary, error) {
+	tmpDir, err := os.MkdirTemp("", "skeleton-archive-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	s.ProjectRootDir = filepath.Join(tmpDir, s.ProjectName)
+	summary, err := generator.Run(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if verify {
+		if err = generator.Verify(ctx, s.ProjectRootDir, s.Reporter); err != nil {
+			return nil, err
+		}
+	}
+
+	s.Reporter.Step("write " + archive)
+	if err = generator.WriteArchive(archive, s.ProjectRootDir); err != nil {
+		return nil, fmt.Errorf("write archive: %w", err)
+	}
+
+	// next steps start in the unpacked project instead of the temporary directory.
+	summary.RootDir = archive
+	summary.NextSteps = generator.NextSteps(extractCommand(archive, s.ProjectName), s.WithDeps)
+	return summary, nil
+}
+
+// extractCommand returns shell command unpacking the archive, which holds the project in the dir, and changing into it.
+func extractCommand(archive, dir string) string {
+	if strings.HasSuffix(strings.ToLower(archive), ".zip") {
+		return fmt.Sprintf("unzip %s && cd %s", archive, dir)
+	}
+	return fmt.Sprintf("tar -xzf %s && cd %s", archive, dir)
+}
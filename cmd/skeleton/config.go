@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// configDirEnv overrides the user config directory, e.g. for CI.
+	configDirEnv = "SKELETON_CONFIG_DIR"
+	defaultsFile = "defaults.yml"
+	presetsDir   = "presets"
+)
+
+// configDir returns directory of the user presets and defaults, ~/.config/skeleton on linux.
+func configDir() (string, error) {
+	if dir := os.Getenv(configDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "skeleton"), nil
+}
+
+// presetPath returns path of the user preset file.
+func presetPath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid preset name %q", name)
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, presetsDir, name+".yml"), nil
+}
+
+// loadPreset returns the user preset or the bundled one, user presets shadow bundled ones with the same name.
+func loadPreset(name string) (*generator.Manifest, error) {
+	path, err := presetPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = os.Stat(path); err == nil {
+		return loadManifest(path)
+	}
+	return generator.FindPreset(name)
+}
+
+// userPresets returns names of the saved presets.
+func userPresets() ([]string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, presetsDir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(paths))
+	for _, p := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(p), ".yml"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// loadDefaults returns remembered default answers of the menus, it's empty if nothing is remembered.
+func loadDefaults() (*generator.Manifest, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, defaultsFile)
+	if _, err = os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return &generator.Manifest{}, nil
+	}
+	return loadManifest(path)
+}
+
+// saveDefaults remembers choices of s as default answers of the menus.
+func saveDefaults(s *generator.Settings) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	m := generator.NewManifest(s)
+	// name and module are different for every project, go version follows the toolchain.
+	m.Name, m.Module, m.GoVersion = "", "", ""
+	return saveManifest(filepath.Join(dir, defaultsFile), m)
+}
+
+func saveManifest(path string, m *generator.Manifest) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runDoctor checks local tools and go environment and prints fixes for problems, it fails if some check failed.
+func runDoctor(c *cli.Context) error {
+	checks := generator.Doctor(c.String("go-version"))
+	if failed := printChecks(os.Stdout, checks); failed != 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// printChecks prints checks table with fixes under the problems and returns the number of failed checks.
+func printChecks(w io.Writer, checks []generator.Check) int {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	failed := 0
+	for _, ch := range checks {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(string(ch.Status)), ch.Name, ch.Detail)
+		if ch.Fix != "" {
+			_, _ = fmt.Fprintf(tw, "\t\tfix: %s\n", ch.Fix)
+		}
+		if ch.Status == generator.CheckFail {
+			failed++
+		}
+	}
+	_ = tw.Flush()
+	return failed
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+)
+
+// runGenerate generates the project, with --output=json errors are also reported as the event.
+func runGenerate(c *cli.Context, s *generator.Settings) error {
+	reporter, err := newReporter(c)
+	if err != nil {
+		return err
+	}
+	s.Reporter = reporter
+
+	err = generate(c, s)
+	if j, ok := reporter.(*generator.JSONReporter); ok && err != nil {
+		j.Error(err)
+	}
+	return err
+}
+
+// newReporter returns reporter chosen by --output, --quiet and --verbose flags.
+func newReporter(c *cli.Context) (generator.Reporter, error) {
+	if c.Bool("quiet") && c.Bool("verbose") {
+		return nil, fmt.Errorf("flags --quiet and --verbose are mutually exclusive")
+	}
+
+	switch c.String("output") {
+	case "json":
+		return generator.NewJSONReporter(os.Stdout), nil
+	case "text":
+		verbosity := generator.Normal
+		if c.Bool("quiet") {
+			verbosity = generator.Quiet
+		}
+		if c.Bool("verbose") {
+			verbosity = generator.Verbose
+		}
+		return generator.NewTextReporter(os.Stderr, verbosity), nil
+	default:
+		return nil, fmt.Errorf("invalid --output %q, expected text or json", c.String("output"))
+	}
+}
+
+func jsonOutput(c *cli.Context) bool {
+	return c.String("output") == "json"
+}
+
+func generate(c *cli.Context, s *generator.Settings) error {
+	dryRun := c.Bool("dry-run") || c.Bool("dump") || c.Bool("diff")
+	if dryRun && c.Bool("verify") {
+		return fmt.Errorf("--verify can't be used with --dry-run, nothing is written to verify")
+	}
+	if dryRun && jsonOutput(c) {
+		return fmt.Errorf("--output=json can't be used with --dry-run, preview is printed as text")
+	}
+
+	archive := c.String("output-archive")
+	switch {
+	case archive != "" && c.String("directory") != "":
+		return fmt.Errorf("--output-archive can't be used with --directory")
+	case archive != "" && dryRun:
+		return fmt.Errorf("--output-archive can't be used with --dry-run, nothing is written")
+	case archive != "" && c.Bool("skip-existing"):
+		return fmt.Errorf("--output-archive can't be used with --skip-existing, archive is always written whole")
+	case archive == "" && c.String("directory") == "":
+		return fmt.Errorf("either --directory or --output-archive is required")
+	}
+	if archive != "" {
+		if err := generator.CheckArchivePath(archive); err != nil {
+			return err
+		}
+		if _, err := os.Stat(archive); err == nil && !c.Bool("force") {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", archive)
+		}
+	}
+
+	s.ProjectRootDir = c.String("directory")
+	if _, err := os.Stat(s.ProjectRootDir); os.IsNotExist(err) && !dryRun && archive == "" {
+		return fmt.Errorf("directory %s not exists", s.ProjectRootDir)
+	}
+	s.WithDeps = c.Bool("with-dependencies")
+	s.Force = c.Bool("force")
+	s.SkipExisting = c.Bool("skip-existing")
+
+	wiz, err := fillSettings(c, s)
+	if err != nil {
+		return err
+	}
+	if err = s.Validate(); err != nil {
+		return err
+	}
+
+	if dryRun {
+		return printPreview(os.Stdout, s, c.Bool("dump"), c.Bool("diff"))
+	}
+	if wiz.interactive() {
+		err = wiz.confirm(func(out io.Writer) error {
+			if err := s.Validate(); err != nil {
+				return err
+			}
+			return printSummary(out, s, archive)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	var summary *generator.Summary
+	if archive != "" {
+		summary, err = generateArchive(ctx, s, archive, c.Bool("verify"))
+		if err != nil {
+			return err
+		}
+	} else {
+		summary, err = generator.Run(ctx, s)
+		var conflictErr *generator.ConflictError
+		if errors.As(err, &conflictErr) {
+			return fmt.Errorf("%w\nuse --force to overwrite them or --skip-existing to write only missing files", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("verify") {
+			if err = generator.Verify(ctx, s.ProjectRootDir, s.Reporter); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = rememberDefaults(c, s); err != nil {
+		return err
+	}
+	s.Reporter.Done(summary)
+	return nil
+}
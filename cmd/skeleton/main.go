@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/dixonwille/wlog/v3"
-	"github.com/dixonwille/wmenu/v5"
 	"github.com/rtsoftSG/skeleton/internal/generator"
 	"github.com/urfave/cli/v2"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
 )
 
 func main() {
@@ -16,166 +20,831 @@ func main() {
 	app := &cli.App{
 		Name:                 "skeleton",
 		Usage:                "A-PLATFORM microservice skeleton generator",
+		Version:              generator.SkeletonVersion(),
 		EnableBashCompletion: true,
 		Commands: []*cli.Command{
 			{
 				Name:    "generate",
 				Aliases: []string{"g"},
 				Usage:   "generate skeleton code",
+				Flags: concatFlags(
+					[]cli.Flag{
+						&cli.StringFlag{
+							Name:    "directory",
+							Aliases: []string{"d"},
+							Usage:   "`PATH` to directory where the new application will be created",
+						},
+						&cli.StringFlag{
+							Name:  "output-archive",
+							Usage: "write the project into .zip, .tar.gz or .tgz `FILE` instead of the directory",
+						},
+						&cli.StringFlag{
+							Name:    "name",
+							Aliases: []string{"n"},
+							Usage:   "application `NAME`, it's the name of cmd/<name> directory, binary and service",
+						},
+						&cli.StringFlag{
+							Name:  "go-version",
+							Usage: "go `VERSION` of the project, e.g. 1.22, version of go toolchain in PATH by default",
+						},
+						&cli.StringFlag{
+							Name:    "module",
+							Aliases: []string{"m"},
+							Usage:   "go module `PATH`, e.g. gitlab.company.local/platform/orders, application name by default",
+						},
+						&cli.StringFlag{
+							Name:    "file",
+							Aliases: []string{"f"},
+							Usage:   "read settings from YAML or JSON manifest `FILE`, use - for stdin",
+						},
+						&cli.StringFlag{
+							Name:    "preset",
+							Aliases: []string{"p"},
+							Usage:   "take choices from bundled or saved preset `NAME`, see skeleton preset list",
+						},
+						&cli.BoolFlag{
+							Name:  "remember",
+							Usage: "remember choices as default answers of the menus",
+						},
+						&cli.BoolFlag{
+							Name:    "with-dependencies",
+							Aliases: []string{"wd"},
+							Usage:   "download service dependencies in vendor directory",
+							Value:   true,
+						},
+					},
+					choiceFlags(),
+					[]cli.Flag{
+						&cli.StringFlag{
+							Name:  "templates-dir",
+							Usage: "`PATH` to directory with templates overriding embedded ones with the same name",
+						},
+						&cli.StringSliceFlag{
+							Name:  "pack",
+							Usage: "`PATH` to template pack directory, may be repeated",
+						},
+						&cli.StringSliceFlag{
+							Name:  "pack-option",
+							Usage: "answer to template pack question as `ID=VALUE`, may be repeated",
+						},
+						&cli.BoolFlag{
+							Name:  "force",
+							Usage: "overwrite files which already exist",
+						},
+						&cli.BoolFlag{
+							Name:  "skip-existing",
+							Usage: "write only files which don't exist yet",
+						},
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "render project in memory and print its directory tree, nothing is written",
+						},
+						&cli.BoolFlag{
+							Name:  "dump",
+							Usage: "print contents of the rendered files, implies --dry-run",
+						},
+						&cli.BoolFlag{
+							Name:  "diff",
+							Usage: "print diff between existing and rendered files, implies --dry-run",
+						},
+						&cli.BoolFlag{
+							Name:  "verify",
+							Usage: "build, vet and test the generated project, fail if something is broken",
+						},
+						&cli.StringFlag{
+							Name:  "output",
+							Usage: "progress `FORMAT`: text or json, json prints an event per line and implies --yes",
+							Value: "text",
+						},
+						&cli.BoolFlag{
+							Name:    "quiet",
+							Aliases: []string{"q"},
+							Usage:   "print only errors",
+						},
+						&cli.BoolFlag{
+							Name:    "verbose",
+							Aliases: []string{"v"},
+							Usage:   "print directories and all external commands with their output",
+						},
+						&cli.BoolFlag{
+							Name:  "no-color",
+							Usage: "print questions without colors, also set by NO_COLOR environment variable",
+						},
+						&cli.BoolFlag{
+							Name:    "yes",
+							Aliases: []string{"y", "non-interactive"},
+							Usage:   "never prompt, fail if some choice is not given by flags",
+						},
+					},
+				),
+				Action: func(c *cli.Context) error {
+					return runGenerate(c, &generatorSettings)
+				},
+			},
+			{
+				Name:  "upgrade",
+				Usage: "re-apply current templates to the generated project",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     "directory",
-						Aliases:  []string{"d"},
-						Usage:    "`PATH` to directory where the new application will be created",
-						Required: true,
+						Name:    "directory",
+						Aliases: []string{"d"},
+						Usage:   "`PATH` to the project root directory",
+						Value:   ".",
 					},
 					&cli.StringFlag{
-						Name:     "name",
-						Aliases:  []string{"n"},
-						Usage:    "application `NAME`",
-						Required: true,
+						Name:  "templates-dir",
+						Usage: "`PATH` to directory with templates overriding embedded ones, replaces the stored one",
 					},
-					&cli.BoolFlag{
-						Name:    "with-dependencies",
-						Aliases: []string{"wd"},
-						Usage:   "download service dependencies in vendor directory",
-						Value:   true,
+					&cli.StringFlag{
+						Name:  "go-version",
+						Usage: "new go `VERSION` of the project, replaces the stored one",
 					},
 				},
 				Action: func(c *cli.Context) error {
-					generatorSettings.ProjectRootDir = c.String("directory")
-					if _, err := os.Stat(generatorSettings.ProjectRootDir); os.IsNotExist(err) {
-						return fmt.Errorf("directory %s not exists", generatorSettings.ProjectRootDir)
+					override := &generator.Manifest{TemplatesDir: c.String("templates-dir"), GoVersion: c.String("go-version")}
+					ctx, stop := interruptContext()
+					defer stop()
+
+					report, err := generator.Upgrade(ctx, c.String("directory"), override)
+					if err != nil {
+						return err
+					}
+
+					log.Printf("upgrade from %s to %s", report.FromVersion, report.ToVersion)
+					if err = printUpgradeReport(report); err != nil {
+						return err
 					}
-					generatorSettings.ProjectName = c.String("name")
-					generatorSettings.WithDeps = c.Bool("with-dependencies")
 
-					if err := runChooseConsulMenu(&generatorSettings); err != nil {
+					log.Print("DONE!")
+					return nil
+				},
+			},
+			{
+				Name:      "add",
+				Usage:     "add a component or an endpoint to the generated project",
+				ArgsUsage: "COMPONENT",
+				Description: "adds one of components to the project generated before: " + componentNames() + ",\n" +
+					"its files, config fields and wiring are merged with the current files as upgrade does",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "endpoint",
+						Usage:     "add an HTTP API endpoint to the generated project",
+						ArgsUsage: "NAME",
+						Description: "adds request and response types, handler, route and a test case of the endpoint\n" +
+							"for the router used by the project, NAME is like create-order",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "directory",
+								Aliases: []string{"d"},
+								Usage:   "`PATH` to the project root directory",
+								Value:   ".",
+							},
+							&cli.StringFlag{
+								Name:    "method",
+								Aliases: []string{"m"},
+								Usage:   "HTTP `METHOD`: " + strings.Join(generator.HTTPMethods, ", "),
+								Value:   "GET",
+							},
+							&cli.StringFlag{
+								Name:     "path",
+								Aliases:  []string{"p"},
+								Usage:    "request `PATH`, e.g. /api/orders",
+								Required: true,
+							},
+						},
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("expected endpoint name")
+							}
+
+							ctx, stop := interruptContext()
+							defer stop()
+
+							paths, err := generator.AddEndpoint(ctx, c.String("directory"), generator.Endpoint{
+								Name:   c.Args().First(),
+								Method: c.String("method"),
+								Path:   c.String("path"),
+							})
+							if err != nil {
+								return err
+							}
+							for _, p := range paths {
+								log.Printf("%-9s %s", generator.Updated, p)
+							}
+
+							log.Print("DONE!")
+							return nil
+						},
+					},
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "directory",
+						Aliases: []string{"d"},
+						Usage:   "`PATH` to the project root directory",
+						Value:   ".",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected one component, one of: %s", componentNames())
+					}
+					component, err := generator.ParseComponent(c.Args().First())
+					if err != nil {
 						return err
 					}
-					if err := runChooseJaegerMenu(&generatorSettings); err != nil {
+
+					ctx, stop := interruptContext()
+					defer stop()
+
+					report, err := generator.Add(ctx, c.String("directory"), component)
+					if err != nil {
 						return err
 					}
-					if err := runChoosePrometheusMenu(&generatorSettings); err != nil {
+					if err = printUpgradeReport(report); err != nil {
 						return err
 					}
-					if err := runChooseLoggerMenu(&generatorSettings); err != nil {
+
+					log.Print("run `go mod tidy && go mod vendor` to fetch dependencies of the component")
+					log.Print("DONE!")
+					return nil
+				},
+			},
+			{
+				Name:      "remove",
+				Usage:     "remove a component from the generated project",
+				ArgsUsage: "COMPONENT",
+				Description: "removes one of components from the project generated before: " + componentNames() + ",\n" +
+					"its package is deleted, config fields and wiring are merged out of the current files as upgrade does",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "directory",
+						Aliases: []string{"d"},
+						Usage:   "`PATH` to the project root directory",
+						Value:   ".",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected one component, one of: %s", componentNames())
+					}
+					component, err := generator.ParseComponent(c.Args().First())
+					if err != nil {
 						return err
 					}
-					if err := runChooseDBMenu(&generatorSettings); err != nil {
+
+					ctx, stop := interruptContext()
+					defer stop()
+
+					report, err := generator.Remove(ctx, c.String("directory"), component)
+					if err != nil {
 						return err
 					}
-					if err := runChooseRouterMenu(&generatorSettings); err != nil {
+					if err = printUpgradeReport(report); err != nil {
 						return err
 					}
 
-					return generator.Run(&generatorSettings)
+					log.Print("run `go mod tidy && go mod vendor` to drop unused dependencies")
+					log.Print("DONE!")
+					return nil
 				},
 			},
+			{
+				Name:  "matrix",
+				Usage: "generate every combination of choices and check it by gofmt and go vet",
+				Description: "each choice flag restricts the enumerated values, it may be repeated or comma separated,\n" +
+					"e.g. --logger zap --db none,postgres --jaeger off",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "logger",
+						Usage: "logger `KINDS`: gokit, zap",
+					},
+					&cli.StringSliceFlag{
+						Name:  "db",
+						Usage: "database `KINDS`: none, clickhouse, postgres",
+					},
+					&cli.StringSliceFlag{
+						Name:  "router",
+						Usage: "router `KINDS`: gorilla-mux, gin",
+					},
+					&cli.StringSliceFlag{
+						Name:  "consul",
+						Usage: "consul `on|off` values",
+					},
+					&cli.StringSliceFlag{
+						Name:  "consul-sync-config",
+						Usage: "consul config sync `on|off` values",
+					},
+					&cli.StringSliceFlag{
+						Name:  "jaeger",
+						Usage: "jaeger `on|off` values",
+					},
+					&cli.StringSliceFlag{
+						Name:  "prometheus",
+						Usage: "prometheus `on|off` values",
+					},
+					&cli.StringFlag{
+						Name:  "go-version",
+						Usage: "go `VERSION` of the generated projects, version of go toolchain in PATH by default",
+					},
+					&cli.StringFlag{
+						Name:  "templates-dir",
+						Usage: "`PATH` to directory with templates overriding embedded ones",
+					},
+					&cli.StringFlag{
+						Name:  "mod-cache",
+						Usage: "`PATH` to go module cache shared by all combinations, GOMODCACHE by default",
+					},
+					&cli.IntFlag{
+						Name:    "jobs",
+						Aliases: []string{"j"},
+						Usage:   "number of combinations checked in parallel",
+						Value:   runtime.NumCPU(),
+					},
+					&cli.BoolFlag{
+						Name:  "keep",
+						Usage: "keep generated projects in the temporary directory",
+					},
+				},
+				Action: runMatrix,
+			},
+			{
+				Name:  "serve",
+				Usage: "serve HTTP API and web form generating projects as zip archives",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "listen",
+						Aliases: []string{"l"},
+						Usage:   "`ADDRESS` to listen on",
+						Value:   ":8080",
+					},
+					&cli.StringFlag{
+						Name:  "go-version",
+						Usage: "default go `VERSION` of the generated projects, version of go toolchain in PATH by default",
+					},
+				},
+				Action: runServe,
+			},
+			presetCommand(),
+			{
+				Name:  "doctor",
+				Usage: "check tools and go environment needed by the generated project",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "go-version",
+						Usage: "go `VERSION` of the generated projects, version of go toolchain in PATH by default",
+					},
+				},
+				Action: runDoctor,
+			},
 		},
 	}
 
 	err := app.Run(os.Args)
+	if errors.Is(err, context.Canceled) {
+		log.Print("interrupted, changes are rolled back")
+		os.Exit(130)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 
 }
 
-func runChooseConsulMenu(s *generator.Settings) error {
-	consulMenu := wmenu.NewMenu("Use consul?")
-	consulMenu.IsYesNo(wmenu.DefY)
-	consulMenu.AddColor(wlog.BrightGreen, wlog.BrightYellow, wlog.None, wlog.Red)
-
-	consulMenu.Action(func(opts []wmenu.Opt) error {
-		s.UseConsul = opts[0].Value.(string) == "yes"
+// interruptContext returns context cancelled by SIGINT or SIGTERM, generator rolls back changes on cancellation.
+// stop restores the default handling of the signals.
+func interruptContext() (ctx context.Context, stop func()) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
 
-		if s.UseConsul {
-			m := wmenu.NewMenu("Sync config with consul?")
-			m.IsYesNo(wmenu.DefY)
-			m.AddColor(wlog.BrightGreen, wlog.BrightYellow, wlog.None, wlog.Red)
-			m.Action(func(opts []wmenu.Opt) error {
-				s.SyncConfigWithConsul = opts[0].Value.(string) == "yes"
-				return nil
-			})
-			return m.Run()
+// printUpgradeReport prints changed files, it fails if some of them have conflicts.
+func printUpgradeReport(report *generator.UpgradeReport) error {
+	for _, f := range report.Files {
+		if f.Action != generator.Unchanged {
+			log.Printf("%-9s %s", f.Action, f.Path)
 		}
+	}
 
-		return nil
-	})
+	if report.RaisedGoVersion != "" {
+		log.Printf("go %s of the project is older than go %s required by pinned modules, the project is moved to go %s",
+			report.RaisedGoVersion, generator.MinGoVersion, generator.MinGoVersion)
+	}
+	if len(report.Upgraded) != 0 {
+		log.Printf("the component change overlaps template changes since %s, these files are merged with templates of %s: %s",
+			report.FromVersion, report.ToVersion, strings.Join(report.Upgraded, ", "))
+	}
 
-	return consulMenu.Run()
+	if conflicts := report.Conflicts(); len(conflicts) != 0 {
+		return fmt.Errorf("%d files have conflicts, resolve conflict markers by hand, "+
+			"%s is updated by the next upgrade, add or remove after that", len(conflicts), generator.ProjectManifestFile)
+	}
+	return nil
 }
 
-func runChooseJaegerMenu(s *generator.Settings) error {
-	jaegerMenu := wmenu.NewMenu("Use jaeger tracer?")
-	jaegerMenu.IsYesNo(wmenu.DefY)
-	jaegerMenu.AddColor(wlog.BrightGreen, wlog.BrightYellow, wlog.None, wlog.Red)
+// choiceFlags are flags of the choices asked by menus.
+func choiceFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "logger",
+			Usage: "logger `KIND`: gokit or zap",
+		},
+		&cli.StringFlag{
+			Name:  "db",
+			Usage: "database `KIND`: none, clickhouse or postgres",
+		},
+		&cli.StringFlag{
+			Name:  "router",
+			Usage: "router `KIND`: gorilla-mux or gin",
+		},
+		&cli.BoolFlag{
+			Name:  "consul",
+			Usage: "register service in consul",
+		},
+		&cli.BoolFlag{
+			Name:  "no-consul",
+			Usage: "do not use consul",
+		},
+		&cli.BoolFlag{
+			Name:  "consul-sync-config",
+			Usage: "sync config with consul",
+		},
+		&cli.BoolFlag{
+			Name:  "no-consul-sync-config",
+			Usage: "do not sync config with consul",
+		},
+		&cli.BoolFlag{
+			Name:  "jaeger",
+			Usage: "use jaeger tracer",
+		},
+		&cli.BoolFlag{
+			Name:  "no-jaeger",
+			Usage: "do not use jaeger tracer",
+		},
+		&cli.BoolFlag{
+			Name:  "prometheus",
+			Usage: "expose prometheus metrics",
+		},
+		&cli.BoolFlag{
+			Name:  "no-prometheus",
+			Usage: "do not expose prometheus metrics",
+		},
+	}
+}
 
-	jaegerMenu.Action(func(opts []wmenu.Opt) error {
-		s.UseJaeger = opts[0].Value.(string) == "yes"
-		return nil
-	})
+func concatFlags(groups ...[]cli.Flag) []cli.Flag {
+	var res []cli.Flag
+	for _, g := range groups {
+		res = append(res, g...)
+	}
+	return res
+}
 
-	return jaegerMenu.Run()
+func componentNames() string {
+	names := make([]string, 0, len(generator.Components))
+	for _, c := range generator.Components {
+		names = append(names, string(c))
+	}
+	return strings.Join(names, ", ")
 }
 
-func runChoosePrometheusMenu(s *generator.Settings) error {
-	prometheusMenu := wmenu.NewMenu("Use prometheus?")
-	prometheusMenu.IsYesNo(wmenu.DefY)
-	prometheusMenu.AddColor(wlog.BrightGreen, wlog.BrightYellow, wlog.None, wlog.Red)
+// menuStep is a single interactive question, asked only if the value wasn't given by manifest file or flags.
+type menuStep struct {
+	flag  string
+	isSet func(m *generator.Manifest) bool
+	// skip reports whether the question makes no sense for current settings.
+	skip func(s *generator.Settings) bool
+	// ask asks the question, def has default answers.
+	ask func(w *wizard, s *generator.Settings, def *generator.Manifest) error
+}
 
-	prometheusMenu.Action(func(opts []wmenu.Opt) error {
-		s.UsePrometheus = opts[0].Value.(string) == "yes"
-		return nil
-	})
+var menuSteps = []menuStep{
+	{
+		flag:  "name",
+		isSet: func(m *generator.Manifest) bool { return m.Name != "" },
+		ask:   askName,
+	},
+	{
+		flag:  "consul",
+		isSet: func(m *generator.Manifest) bool { return m.UseConsul != nil },
+		ask:   askConsul,
+	},
+	{
+		flag:  "consul-sync-config",
+		isSet: func(m *generator.Manifest) bool { return m.SyncConfigWithConsul != nil },
+		skip:  func(s *generator.Settings) bool { return !s.UseConsul },
+		ask:   askConsulSync,
+	},
+	{
+		flag:  "jaeger",
+		isSet: func(m *generator.Manifest) bool { return m.UseJaeger != nil },
+		ask:   askJaeger,
+	},
+	{
+		flag:  "prometheus",
+		isSet: func(m *generator.Manifest) bool { return m.UsePrometheus != nil },
+		ask:   askPrometheus,
+	},
+	{
+		flag:  "logger",
+		isSet: func(m *generator.Manifest) bool { return m.Logger != "" },
+		ask:   askLogger,
+	},
+	{
+		flag:  "db",
+		isSet: func(m *generator.Manifest) bool { return m.Database != "" },
+		ask:   askDB,
+	},
+	{
+		flag:  "router",
+		isSet: func(m *generator.Manifest) bool { return m.Router != "" },
+		ask:   askRouter,
+	},
+}
+
+// fillSettings copies choices given by preset, manifest file and flags into s and asks the rest by the wizard.
+// Flags take precedence over the manifest file, the file takes precedence over the preset.
+// The wizard offers remembered defaults as default answers.
+// In non-interactive mode missing choices are reported as error before anything is asked.
+func fillSettings(c *cli.Context, s *generator.Settings) (*wizard, error) {
+	m := &generator.Manifest{}
+	if name := c.String("preset"); name != "" {
+		var err error
+		if m, err = loadPreset(name); err != nil {
+			return nil, err
+		}
+	}
+	if path := c.String("file"); path != "" {
+		fm, err := loadManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		m.Override(fm)
+	}
+
+	fm, err := manifestFromFlags(c)
+	if err != nil {
+		return nil, err
+	}
+	m.Override(fm)
+	if err = m.Validate(); err != nil {
+		return nil, err
+	}
+	m.Apply(s)
+	if s.GoVersion == "" {
+		s.GoVersion = generator.DetectGoVersion()
+	}
+
+	packs, err := generator.LoadPacks(s.Packs)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Bool("yes") || jsonOutput(c) {
+		var missing []string
+		for _, step := range menuSteps {
+			if !step.isSet(m) && (step.skip == nil || !step.skip(s)) {
+				missing = append(missing, "--"+step.flag)
+			}
+		}
+		for _, q := range unansweredPackQuestions(packs, s) {
+			if q.Default == "" {
+				missing = append(missing, "--pack-option "+q.ID+"=...")
+				continue
+			}
+			// default answer is recorded, so upgrade doesn't depend on later changes of the pack.
+			if s.PackOptions == nil {
+				s.PackOptions = map[string]string{}
+			}
+			s.PackOptions[q.ID] = q.Default
+		}
+		if len(missing) != 0 {
+			return nil, fmt.Errorf("non-interactive mode, missing values for: %s", strings.Join(missing, ", "))
+		}
+		return newWizard(s, m, false), nil
+	}
 
-	return prometheusMenu.Run()
+	def, err := loadDefaults()
+	if err != nil {
+		return nil, err
+	}
+	w := newWizard(s, def, !c.Bool("no-color") && os.Getenv("NO_COLOR") == "")
+
+	for _, step := range menuSteps {
+		if step.isSet(m) {
+			continue
+		}
+		step := step
+		var skip func() bool
+		if step.skip != nil {
+			skip = func() bool { return step.skip(s) }
+		}
+		w.add(skip, func(def *generator.Manifest) error { return step.ask(w, s, def) })
+	}
+	for _, q := range unansweredPackQuestions(packs, s) {
+		q := q
+		w.add(nil, func(def *generator.Manifest) error { return askPackQuestion(w, q, s, def) })
+	}
+
+	return w, w.run(0)
 }
 
-func runChooseLoggerMenu(s *generator.Settings) error {
-	loggerMenu := wmenu.NewMenu("Select logger")
+func loadManifest(path string) (*generator.Manifest, error) {
+	if path == "-" {
+		return generator.LoadManifest(os.Stdin)
+	}
 
-	loggerMenu.LoopOnInvalid()
-	loggerMenu.AddColor(wlog.BrightGreen, wlog.BrightYellow, wlog.None, wlog.Red)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
 
-	loggerMenu.Action(func(opts []wmenu.Opt) error {
-		s.Logger = opts[0].Value.(generator.LoggerChoice)
-		return nil
+	m, err := generator.LoadManifest(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// manifestFromFlags returns manifest filled by choices given in command line.
+func manifestFromFlags(c *cli.Context) (*generator.Manifest, error) {
+	m := &generator.Manifest{
+		Name:         c.String("name"),
+		Module:       c.String("module"),
+		GoVersion:    c.String("go-version"),
+		TemplatesDir: c.String("templates-dir"),
+	}
+
+	if c.IsSet("pack") {
+		m.Packs = c.StringSlice("pack")
+	}
+	for _, o := range c.StringSlice("pack-option") {
+		id, v := o, ""
+		if i := strings.IndexByte(o, '='); i >= 0 {
+			id, v = o[:i], o[i+1:]
+		}
+		if id == "" || v == "" {
+			return nil, fmt.Errorf("invalid --pack-option %q, expected ID=VALUE", o)
+		}
+		if m.PackOptions == nil {
+			m.PackOptions = map[string]string{}
+		}
+		m.PackOptions[id] = v
+	}
+
+	var err error
+	if c.IsSet("logger") {
+		if m.Logger, err = generator.ParseLoggerChoice(c.String("logger")); err != nil {
+			return nil, err
+		}
+	}
+	if c.IsSet("db") {
+		if m.Database, err = generator.ParseDBChoice(c.String("db")); err != nil {
+			return nil, err
+		}
+	}
+	if c.IsSet("router") {
+		if m.Router, err = generator.ParseRouterChoice(c.String("router")); err != nil {
+			return nil, err
+		}
+	}
+	if m.UseConsul, err = boolFlag(c, "consul"); err != nil {
+		return nil, err
+	}
+	if m.SyncConfigWithConsul, err = boolFlag(c, "consul-sync-config"); err != nil {
+		return nil, err
+	}
+	if m.UseJaeger, err = boolFlag(c, "jaeger"); err != nil {
+		return nil, err
+	}
+	if m.UsePrometheus, err = boolFlag(c, "prometheus"); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// boolFlag returns value of --name/--no-name flags pair or nil if none of them is set.
+func boolFlag(c *cli.Context, name string) (*bool, error) {
+	var v bool
+	switch {
+	case c.IsSet(name) && c.IsSet("no-"+name):
+		return nil, fmt.Errorf("flags --%s and --no-%s are mutually exclusive", name, name)
+	case c.IsSet(name):
+		v = c.Bool(name)
+	case c.IsSet("no-" + name):
+		v = !c.Bool("no-" + name)
+	default:
+		return nil, nil
+	}
+	return &v, nil
+}
+
+func askName(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	name, err := w.input("Application name", def.Name, func(v string) error {
+		return (&generator.Manifest{Name: v}).Validate()
 	})
+	if err != nil {
+		return err
+	}
+	s.ProjectName = name
+	return nil
+}
 
-	loggerMenu.Option(string(generator.GoKit), generator.GoKit, true, nil)
-	loggerMenu.Option(string(generator.Zap), generator.Zap, false, nil)
-	return loggerMenu.Run()
+// askYesNo asks yes/no question, the default answer is yes unless def is no.
+func askYesNo(w *wizard, question string, def *bool, v *bool) error {
+	answer, err := w.yesNo(question, def == nil || *def)
+	if err != nil {
+		return err
+	}
+	*v = answer
+	return nil
 }
 
-func runChooseDBMenu(s *generator.Settings) error {
-	dbMenu := wmenu.NewMenu("Select database")
+func askConsul(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	if err := askYesNo(w, "Use consul?", def.UseConsul, &s.UseConsul); err != nil {
+		return err
+	}
+	if !s.UseConsul {
+		s.SyncConfigWithConsul = false
+	}
+	return nil
+}
 
-	dbMenu.LoopOnInvalid()
-	dbMenu.AddColor(wlog.BrightGreen, wlog.BrightYellow, wlog.None, wlog.Red)
+func askConsulSync(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	return askYesNo(w, "Sync config with consul?", def.SyncConfigWithConsul, &s.SyncConfigWithConsul)
+}
 
-	dbMenu.Action(func(opts []wmenu.Opt) error {
-		s.Database = opts[0].Value.(generator.DBChoice)
-		return nil
-	})
-	dbMenu.Option(string(generator.NoDb), generator.NoDb, true, nil)
-	dbMenu.Option(string(generator.Clickhouse), generator.Clickhouse, false, nil)
-	dbMenu.Option(string(generator.Postgresql), generator.Postgresql, false, nil)
-	return dbMenu.Run()
+func askJaeger(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	return askYesNo(w, "Use jaeger tracer?", def.UseJaeger, &s.UseJaeger)
+}
+
+func askPrometheus(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	return askYesNo(w, "Use prometheus?", def.UsePrometheus, &s.UsePrometheus)
+}
+
+func askLogger(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	titles, selected := make([]string, len(generator.Loggers)), 0
+	for i, l := range generator.Loggers {
+		titles[i] = l.Title()
+		if l == def.Logger {
+			selected = i
+		}
+	}
+	i, err := w.choose("Select logger", titles, selected)
+	if err != nil {
+		return err
+	}
+	s.Logger = generator.Loggers[i]
+	return nil
 }
 
-func runChooseRouterMenu(s *generator.Settings) error {
-	routerMenu := wmenu.NewMenu("Select router")
+func askDB(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	titles, selected := make([]string, len(generator.Databases)), 0
+	for i, d := range generator.Databases {
+		titles[i] = d.Title()
+		if d == def.Database {
+			selected = i
+		}
+	}
+	i, err := w.choose("Select database", titles, selected)
+	if err != nil {
+		return err
+	}
+	s.Database = generator.Databases[i]
+	return nil
+}
 
-	routerMenu.LoopOnInvalid()
-	routerMenu.AddColor(wlog.BrightGreen, wlog.BrightYellow, wlog.None, wlog.Red)
+func askRouter(w *wizard, s *generator.Settings, def *generator.Manifest) error {
+	titles, selected := make([]string, len(generator.Routers)), 0
+	for i, r := range generator.Routers {
+		titles[i] = r.Title()
+		switch r {
+		case generator.GorillaMux:
+			titles[i] += ", go-kit endpoints"
+		case generator.GIN:
+			titles[i] += ", gin endpoints"
+		}
+		if r == def.Router {
+			selected = i
+		}
+	}
+	i, err := w.choose("Select router", titles, selected)
+	if err != nil {
+		return err
+	}
+	s.Router = generator.Routers[i]
+	return nil
+}
 
-	routerMenu.Action(func(opts []wmenu.Opt) error {
-		s.Router = opts[0].Value.(generator.RouterChoice)
+// rememberDefaults saves choices as default answers of the menus if --remember is given.
+func rememberDefaults(c *cli.Context, s *generator.Settings) error {
+	if !c.Bool("remember") {
 		return nil
-	})
-	routerMenu.Option(string(generator.GorillaMux)+", go-kit endpoints", generator.GorillaMux, true, nil)
-	routerMenu.Option(string(generator.GIN)+", gin endpoints", generator.GIN, false, nil)
-	return routerMenu.Run()
+	}
+	s.Reporter.Step("remember choices as defaults")
+	if err := saveDefaults(s); err != nil {
+		return fmt.Errorf("remember defaults: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"github.com/urfave/cli/v2"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// runMatrix renders every combination of choices allowed by flags into a temporary directory,
+// checks it by gofmt and go vet and prints pass/fail table.
+func runMatrix(c *cli.Context) error {
+	axes, err := matrixAxes(c)
+	if err != nil {
+		return err
+	}
+
+	base := generator.Settings{
+		ProjectName:  "matrix",
+		GoVersion:    c.String("go-version"),
+		TemplatesDir: c.String("templates-dir"),
+	}
+	if base.GoVersion == "" {
+		base.GoVersion = generator.DetectGoVersion()
+	}
+	combinations := generator.Combinations(base, axes)
+	for i := range combinations {
+		if err = combinations[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "skeleton-matrix-")
+	if err != nil {
+		return err
+	}
+	if c.Bool("keep") {
+		log.Printf("projects are kept in %s", tmpDir)
+	} else {
+		defer os.RemoveAll(tmpDir)
+	}
+
+	// all combinations share module cache, so dependencies are downloaded once.
+	env := []string{"GOFLAGS=-mod=mod"}
+	if cache := c.String("mod-cache"); cache != "" {
+		abs, err := filepath.Abs(cache)
+		if err != nil {
+			return err
+		}
+		env = append(env, "GOMODCACHE="+abs)
+	}
+
+	jobs := c.Int("jobs")
+	if jobs < 1 {
+		jobs = 1
+	}
+	log.Printf("check %d combinations ...", len(combinations))
+
+	results := make([]generator.MatrixResult, len(combinations))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				dir := filepath.Join(tmpDir, strconv.Itoa(i+1))
+				results[i] = generator.CheckCombination(combinations[i], dir, env)
+				status := "ok"
+				if !results[i].Passed() {
+					status = results[i].Stage + " failed"
+				}
+				log.Printf("%d/%d %s", i+1, len(combinations), status)
+			}
+		}()
+	}
+	for i := range combinations {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	failed := printMatrix(os.Stdout, results)
+	if failed != 0 {
+		return fmt.Errorf("%d of %d combinations failed", failed, len(results))
+	}
+	log.Print("DONE!")
+	return nil
+}
+
+// printMatrix prints results table followed by outputs of failed checks, it returns the number of failures.
+func printMatrix(w io.Writer, results []generator.MatrixResult) int {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "#\tLOGGER\tDB\tROUTER\tCONSUL\tSYNC\tJAEGER\tPROMETHEUS\tRESULT")
+
+	failed := 0
+	for i, r := range results {
+		s := r.Settings
+		result := "PASS"
+		if !r.Passed() {
+			result = "FAIL (" + r.Stage + ")"
+			failed++
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", i+1, s.Logger, s.Database, s.Router,
+			onOff(s.UseConsul), onOff(s.SyncConfigWithConsul), onOff(s.UseJaeger), onOff(s.UsePrometheus), result)
+	}
+	_ = tw.Flush()
+
+	for i, r := range results {
+		if r.Passed() {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "\n==> #%d %s <==\n%s\n", i+1, r.Stage, strings.TrimSpace(r.Output))
+	}
+	return failed
+}
+
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
+
+// matrixAxes returns values of the choices given by flags, flags may be repeated or comma separated.
+func matrixAxes(c *cli.Context) (generator.MatrixAxes, error) {
+	var axes generator.MatrixAxes
+
+	for _, v := range splitValues(c.StringSlice("logger")) {
+		l, err := generator.ParseLoggerChoice(v)
+		if err != nil {
+			return axes, err
+		}
+		axes.Loggers = append(axes.Loggers, l)
+	}
+	for _, v := range splitValues(c.StringSlice("db")) {
+		d, err := generator.ParseDBChoice(v)
+		if err != nil {
+			return axes, err
+		}
+		axes.Databases = append(axes.Databases, d)
+	}
+	for _, v := range splitValues(c.StringSlice("router")) {
+		r, err := generator.ParseRouterChoice(v)
+		if err != nil {
+			return axes, err
+		}
+		axes.Routers = append(axes.Routers, r)
+	}
+
+	boolAxes := []struct {
+		flag  string
+		value *[]bool
+	}{
+		{"consul", &axes.UseConsul},
+		{"consul-sync-config", &axes.SyncConfigWithConsul},
+		{"jaeger", &axes.UseJaeger},
+		{"prometheus", &axes.UsePrometheus},
+	}
+	for _, a := range boolAxes {
+		for _, v := range splitValues(c.StringSlice(a.flag)) {
+			b, err := parseOnOff(v)
+			if err != nil {
+				return axes, fmt.Errorf("invalid --%s %q: %w", a.flag, v, err)
+			}
+			*a.value = append(*a.value, b)
+		}
+	}
+
+	return axes, nil
+}
+
+func splitValues(values []string) []string {
+	var res []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				res = append(res, part)
+			}
+		}
+	}
+	return res
+}
+
+func parseOnOff(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "on", "yes":
+		return true, nil
+	case "off", "no":
+		return false, nil
+	default:
+		return strconv.ParseBool(v)
+	}
+}
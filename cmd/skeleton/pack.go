@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"strconv"
+)
+
+// unansweredPackQuestions returns questions of packs which have no answer in settings.
+func unansweredPackQuestions(packs []*generator.Pack, s *generator.Settings) []generator.PackQuestion {
+	var res []generator.PackQuestion
+	for _, p := range packs {
+		for _, q := range p.Questions {
+			if _, ok := s.PackOptions[q.ID]; !ok {
+				res = append(res, q)
+			}
+		}
+	}
+	return res
+}
+
+func askPackQuestion(w *wizard, q generator.PackQuestion, s *generator.Settings, def *generator.Manifest) error {
+	if v, ok := def.PackOptions[q.ID]; ok {
+		q.Default = v
+	}
+	if s.PackOptions == nil {
+		s.PackOptions = map[string]string{}
+	}
+
+	if q.IsYesNo() {
+		yes := true
+		if v, err := strconv.ParseBool(q.Default); err == nil {
+			yes = v
+		}
+		answer, err := w.yesNo(q.Prompt, yes)
+		if err != nil {
+			return err
+		}
+		s.PackOptions[q.ID] = strconv.FormatBool(answer)
+		return nil
+	}
+
+	selected := 0
+	for i, o := range q.Options {
+		if o == q.Default {
+			selected = i
+		}
+	}
+	i, err := w.choose(q.Prompt, q.Options, selected)
+	if err != nil {
+		return err
+	}
+	s.PackOptions[q.ID] = q.Options[i]
+	return nil
+}
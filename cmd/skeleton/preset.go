@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+	"log"
+	"os"
+	"text/tabwriter"
+)
+
+func presetCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "preset",
+		Usage: "list, show and save presets of choices",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "list bundled and saved presets",
+				Action: listPresets,
+			},
+			{
+				Name:      "show",
+				Usage:     "print choices of the preset",
+				ArgsUsage: "NAME",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected preset name")
+					}
+					m, err := loadPreset(c.Args().First())
+					if err != nil {
+						return err
+					}
+					data, err := yaml.Marshal(m)
+					if err != nil {
+						return err
+					}
+					_, err = os.Stdout.Write(data)
+					return err
+				},
+			},
+			{
+				Name:      "save",
+				Usage:     "save choices given by flags, file or other preset as the user preset",
+				ArgsUsage: "NAME",
+				Flags: concatFlags(
+					[]cli.Flag{
+						&cli.StringFlag{
+							Name:    "file",
+							Aliases: []string{"f"},
+							Usage:   "read choices from YAML or JSON manifest `FILE`, use - for stdin",
+						},
+						&cli.StringFlag{
+							Name:    "preset",
+							Aliases: []string{"p"},
+							Usage:   "start from the preset `NAME`",
+						},
+						&cli.StringFlag{
+							Name:  "go-version",
+							Usage: "go `VERSION` of the projects",
+						},
+					},
+					choiceFlags(),
+					[]cli.Flag{
+						&cli.StringFlag{
+							Name:  "templates-dir",
+							Usage: "`PATH` to directory with templates overriding embedded ones with the same name",
+						},
+						&cli.StringSliceFlag{
+							Name:  "pack",
+							Usage: "`PATH` to template pack directory, may be repeated",
+						},
+						&cli.StringSliceFlag{
+							Name:  "pack-option",
+							Usage: "answer to template pack question as `ID=VALUE`, may be repeated",
+						},
+					},
+				),
+				Action: savePreset,
+			},
+			{
+				Name:      "delete",
+				Usage:     "delete the user preset",
+				ArgsUsage: "NAME",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected preset name")
+					}
+					path, err := presetPath(c.Args().First())
+					if err != nil {
+						return err
+					}
+					if err = os.Remove(path); err != nil {
+						return err
+					}
+					log.Printf("preset %s is deleted", c.Args().First())
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func listPresets(*cli.Context) error {
+	saved, err := userPresets()
+	if err != nil {
+		return err
+	}
+	isSaved := make(map[string]bool, len(saved))
+	for _, name := range saved {
+		isSaved[name] = true
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range generator.Presets {
+		if isSaved[p.Name] {
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%s\tbundled\t%s\n", p.Name, p.Description)
+	}
+	for _, name := range saved {
+		_, _ = fmt.Fprintf(tw, "%s\tsaved\t\n", name)
+	}
+	return tw.Flush()
+}
+
+func savePreset(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected preset name")
+	}
+	path, err := presetPath(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	m := &generator.Manifest{}
+	if name := c.String("preset"); name != "" {
+		if m, err = loadPreset(name); err != nil {
+			return err
+		}
+	}
+	if file := c.String("file"); file != "" {
+		fm, err := loadManifest(file)
+		if err != nil {
+			return err
+		}
+		m.Override(fm)
+	}
+	fm, err := manifestFromFlags(c)
+	if err != nil {
+		return err
+	}
+	m.Override(fm)
+	if err = m.Validate(); err != nil {
+		return err
+	}
+	// preset is shared by projects, so it has no project name and module.
+	m.Name, m.Module = "", ""
+
+	if err = saveManifest(path, m); err != nil {
+		return err
+	}
+	log.Printf("preset is saved to %s", path)
+	return nil
+}
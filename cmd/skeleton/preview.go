@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"github.com/rtsoftSG/skeleton/internal/merge"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// previewNode is a file or a directory of the previewed project tree.
+type previewNode struct {
+	name     string
+	file     *generator.File
+	status   string
+	children map[string]*previewNode
+}
+
+// printPreview renders the project in memory and prints its tree,
+// dump prints contents of all files, diff prints changes against files which already exist in the project directory.
+func printPreview(w io.Writer, s *generator.Settings, dump, diff bool) error {
+	files, err := generator.RenderProject(s)
+	if err != nil {
+		return err
+	}
+
+	root := &previewNode{name: s.ProjectRootDir, children: map[string]*previewNode{}}
+
+	var diffs bytes.Buffer
+	for i := range files {
+		f := &files[i]
+		n := root.add(f.Path)
+		n.file = f
+
+		current, err := os.ReadFile(filepath.Join(s.ProjectRootDir, filepath.FromSlash(f.Path)))
+		switch {
+		case os.IsNotExist(err):
+			n.status = "new"
+			current = nil
+		case err != nil:
+			return err
+		case bytes.Equal(current, f.Content):
+			n.status = "unchanged"
+		default:
+			n.status = "changed"
+		}
+
+		if diff && n.status != "unchanged" {
+			oldName := "a/" + f.Path
+			if n.status == "new" {
+				oldName = "/dev/null"
+			}
+			diffs.Write(merge.Unified(current, f.Content, oldName, "b/"+f.Path, 3))
+		}
+	}
+
+	fmt.Fprintln(w, root.name)
+	root.print(w, "")
+	if s.WithDeps {
+		fmt.Fprintln(w, "go.sum and vendor/ will be filled by go mod tidy and go mod vendor")
+	}
+
+	if dump {
+		for _, f := range files {
+			fmt.Fprintf(w, "\n==> %s <==\n", f.Path)
+			_, _ = w.Write(f.Content)
+			if !bytes.HasSuffix(f.Content, []byte("\n")) {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+
+	if diff && diffs.Len() != 0 {
+		fmt.Fprintln(w)
+		_, _ = diffs.WriteTo(w)
+	}
+
+	return nil
+}
+
+// add creates nodes for all elements of slash separated path p and returns the last one.
+func (n *previewNode) add(p string) *previewNode {
+	for _, name := range strings.Split(path.Clean(p), "/") {
+		child, ok := n.children[name]
+		if !ok {
+			child = &previewNode{name: name, children: map[string]*previewNode{}}
+			n.children[name] = child
+		}
+		n = child
+	}
+	return n
+}
+
+func (n *previewNode) print(w io.Writer, indent string) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		child := n.children[name]
+		branch, nextIndent := "├── ", indent+"│   "
+		if i == len(names)-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+
+		if child.file == nil {
+			fmt.Fprintf(w, "%s%s%s/\n", indent, branch, name)
+			child.print(w, nextIndent)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s%s (%d B, %s)\n", indent, branch, name, len(child.file.Content), child.status)
+	}
+}
+
+// printSummary prints settings of the project and files which are going to be written into the directory
+// or the archive.
+func printSummary(w io.Writer, s *generator.Settings, archive string) error {
+	onOffConsul := onOff(s.UseConsul)
+	if s.UseConsul && s.SyncConfigWithConsul {
+		onOffConsul += ", config sync"
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	rows := [][2]string{
+		{"name", s.ProjectName},
+		{"module", s.Module()},
+		{"go version", s.Go()},
+		{"logger", s.Logger.Title()},
+		{"database", s.Database.Title()},
+		{"router", s.Router.Title()},
+		{"consul", onOffConsul},
+		{"jaeger", onOff(s.UseJaeger)},
+		{"prometheus", onOff(s.UsePrometheus)},
+	}
+	if s.TemplatesDir != "" {
+		rows = append(rows, [2]string{"templates", s.TemplatesDir})
+	}
+	for _, p := range s.Packs {
+		rows = append(rows, [2]string{"pack", p})
+	}
+	ids := make([]string, 0, len(s.PackOptions))
+	for id := range s.PackOptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		rows = append(rows, [2]string{id, s.PackOptions[id]})
+	}
+
+	fmt.Fprintln(tw, "Settings:")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "  %s\t%s\n", r[0], r[1])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nFiles:")
+	if archive == "" {
+		return printPreview(w, s, false, false)
+	}
+
+	files, err := generator.RenderProject(s)
+	if err != nil {
+		return err
+	}
+	root := &previewNode{name: archive, children: map[string]*previewNode{}}
+	for i := range files {
+		n := root.add(files[i].Path)
+		n.file, n.status = &files[i], "new"
+	}
+	fmt.Fprintln(w, root.name)
+	root.print(w, "")
+	if s.WithDeps {
+		fmt.Fprintln(w, "go.sum and vendor/ will be filled by go mod tidy and go mod vendor")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"github.com/rtsoftSG/skeleton/internal/server"
+	"github.com/urfave/cli/v2"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runServe serves the generator until SIGINT or SIGTERM, requests in progress are finished before exit.
+func runServe(c *cli.Context) error {
+	goVersion := c.String("go-version")
+	if goVersion == "" {
+		goVersion = generator.DetectGoVersion()
+	}
+	if err := (&generator.Manifest{GoVersion: goVersion}).Validate(); err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              c.String("listen"),
+		Handler:           server.New(goVersion),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("listen on %s ...", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-errCh:
+		return err
+	case <-sig:
+	}
+
+	log.Print("shutdown ...")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
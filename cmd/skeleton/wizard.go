@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errBack is returned by prompts when the user asks to return to the previous question.
+var errBack = errors.New("back to the previous question")
+
+const (
+	colorQuestion = "\033[92m"
+	colorDefault  = "\033[93m"
+	colorError    = "\033[31m"
+	colorReset    = "\033[0m"
+)
+
+// wizardStep is a question of the wizard.
+type wizardStep struct {
+	// skip reports whether the question makes no sense for current answers.
+	skip func() bool
+	// ask asks the question, def has default answers.
+	ask func(def *generator.Manifest) error
+}
+
+// wizard asks questions one by one, any question may be answered by "b" to return to the previous one.
+type wizard struct {
+	in    *bufio.Reader
+	out   io.Writer
+	color bool
+
+	settings *generator.Settings
+	// defaults are remembered answers offered for the questions asked for the first time.
+	defaults *generator.Manifest
+	steps    []wizardStep
+	// asked are indexes of the answered steps in order, back re-asks the last one.
+	asked    []int
+	answered map[int]bool
+}
+
+func newWizard(s *generator.Settings, defaults *generator.Manifest, color bool) *wizard {
+	return &wizard{
+		in:       bufio.NewReader(os.Stdin),
+		out:      os.Stdout,
+		color:    color,
+		settings: s,
+		defaults: defaults,
+		answered: map[int]bool{},
+	}
+}
+
+func (w *wizard) add(skip func() bool, ask func(def *generator.Manifest) error) {
+	w.steps = append(w.steps, wizardStep{skip: skip, ask: ask})
+}
+
+// interactive reports whether the wizard has asked something.
+func (w *wizard) interactive() bool {
+	return len(w.asked) != 0
+}
+
+// run asks all questions starting from step i.
+func (w *wizard) run(i int) error {
+	for i < len(w.steps) {
+		st := w.steps[i]
+		if st.skip != nil && st.skip() {
+			i++
+			continue
+		}
+
+		w.printf("\n[%d/%d] ", len(w.asked)+1, len(w.asked)+w.remaining(i))
+		def := w.defaults
+		if w.answered[i] {
+			// re-asked question offers the previous answer.
+			def = generator.NewManifest(w.settings)
+		}
+
+		err := st.ask(def)
+		if errors.Is(err, errBack) {
+			prev, ok := w.back()
+			if ok {
+				i = prev
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		w.asked = append(w.asked, i)
+		w.answered[i] = true
+		i++
+	}
+	return nil
+}
+
+// remaining returns the number of questions from step i which are going to be asked with current answers.
+func (w *wizard) remaining(i int) int {
+	n := 0
+	for ; i < len(w.steps); i++ {
+		if w.steps[i].skip == nil || !w.steps[i].skip() {
+			n++
+		}
+	}
+	return n
+}
+
+// back forgets the last answered step and returns its index.
+func (w *wizard) back() (int, bool) {
+	if len(w.asked) == 0 {
+		w.errorf("it's the first question")
+		return 0, false
+	}
+	last := w.asked[len(w.asked)-1]
+	w.asked = w.asked[:len(w.asked)-1]
+	return last, true
+}
+
+// confirm prints the summary and asks whether to proceed, back returns to the last question.
+func (w *wizard) confirm(summary func(out io.Writer) error) error {
+	for {
+		w.printf("\n")
+		if err := summary(w.out); err != nil {
+			return err
+		}
+
+		ok, err := w.yesNo("Generate the project?", true)
+		if errors.Is(err, errBack) {
+			prev, hasPrev := w.back()
+			if !hasPrev {
+				continue
+			}
+			if err = w.run(prev); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("cancelled")
+		}
+		return nil
+	}
+}
+
+// choose asks to select one of options, it returns index of the selected option.
+func (w *wizard) choose(question string, options []string, def int) (int, error) {
+	for {
+		w.printf("%s\n", w.paint(colorQuestion, question))
+		for i, o := range options {
+			mark := " "
+			if i == def {
+				mark = w.paint(colorDefault, "*")
+			}
+			w.printf("%s %d) %s\n", mark, i+1, o)
+		}
+		w.printf("choice [%d]%s: ", def+1, w.backHint())
+
+		answer, err := w.readLine()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case answer == "":
+			return def, nil
+		case isBack(answer):
+			return 0, errBack
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		w.errorf("enter number from 1 to %d", len(options))
+	}
+}
+
+// yesNo asks yes/no question.
+func (w *wizard) yesNo(question string, def bool) (bool, error) {
+	hint := "Y/n"
+	if !def {
+		hint = "y/N"
+	}
+	for {
+		w.printf("%s (%s%s) ", w.paint(colorQuestion, question), hint, w.backHint())
+
+		answer, err := w.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		if isBack(answer) {
+			return false, errBack
+		}
+		w.errorf("enter y or n")
+	}
+}
+
+// input asks for a text value, check validates it.
+func (w *wizard) input(question, def string, check func(string) error) (string, error) {
+	for {
+		w.printf("%s", w.paint(colorQuestion, question))
+		if def != "" {
+			w.printf(" [%s]", def)
+		}
+		w.printf("%s: ", w.backHint())
+
+		answer, err := w.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		// on the first question back isn't offered, so b is a value, e.g. the project name.
+		if len(w.asked) != 0 && isBack(answer) {
+			return "", errBack
+		}
+		if answer == "" {
+			w.errorf("value is required")
+			continue
+		}
+		if err = check(answer); err != nil {
+			w.errorf("%v", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+func (w *wizard) backHint() string {
+	if len(w.asked) == 0 {
+		return ""
+	}
+	return ", b - back"
+}
+
+func (w *wizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		if errors.Is(err, io.EOF) {
+			return "", errors.New("no answer, input is closed")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (w *wizard) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(w.out, format, args...)
+}
+
+func (w *wizard) errorf(format string, args ...interface{}) {
+	w.printf("%s\n", w.paint(colorError, fmt.Sprintf(format, args...)))
+}
+
+func (w *wizard) paint(color, s string) string {
+	if !w.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func isBack(answer string) bool {
+	switch strings.ToLower(answer) {
+	case "b", "back", "<":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package skeleton
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirFS is FS writing into the directory on disk.
+type DirFS string
+
+// MkdirAll creates the directory with all parents.
+func (d DirFS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(d.join(path), perm)
+}
+
+// WriteFile writes the file.
+func (d DirFS) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(d.join(path), data, perm)
+}
+
+func (d DirFS) join(path string) string {
+	return filepath.Join(string(d), filepath.FromSlash(path))
+}
+
+// MemFS is in-memory FS, it's also fs.FS, so the written project may be read by fs.ReadFile, fs.WalkDir etc.
+type MemFS map[string][]byte
+
+// MkdirAll does nothing, directories are implied by file paths.
+func (m MemFS) MkdirAll(string, fs.FileMode) error {
+	return nil
+}
+
+// WriteFile stores copy of data.
+func (m MemFS) WriteFile(path string, data []byte, _ fs.FileMode) error {
+	m[path] = append([]byte(nil), data...)
+	return nil
+}
+
+// Open implements fs.FS, directories are the parents of stored files.
+func (m MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := m[name]; ok {
+		return &memFile{info: memInfo{name: path.Base(name), size: int64(len(data))}, Reader: bytes.NewReader(data)}, nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := make(map[string]bool) // base name of the child is mapped to whether it's a directory.
+	for p := range m {
+		if rest := strings.TrimPrefix(p, prefix); rest != p || prefix == "" {
+			i := strings.IndexByte(rest, '/')
+			if i < 0 {
+				children[rest] = false
+			} else {
+				children[rest[:i]] = true
+			}
+		}
+	}
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for child, dir := range children {
+		entries = append(entries, memInfo{name: child, size: int64(len(m[path.Join(name, child)])), dir: dir})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return &memDir{info: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// memInfo is fs.FileInfo and fs.DirEntry of MemFS files and directories.
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string               { return i.name }
+func (i memInfo) Size() int64                { return i.size }
+func (i memInfo) ModTime() time.Time         { return time.Time{} }
+func (i memInfo) IsDir() bool                { return i.dir }
+func (i memInfo) Sys() interface{}           { return nil }
+func (i memInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i memInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// memFile is the opened MemFS file.
+type memFile struct {
+	*bytes.Reader
+	info memInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is the opened MemFS directory.
+type memDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	d.offset += len(rest)
+	return rest, nil
+}
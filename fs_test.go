@@ -0,0 +1,33 @@
+package skeleton
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemFS(t *testing.T) {
+	m := MemFS{}
+	s := &Settings{ProjectName: "orders", GoVersion: "1.21", Logger: Zap, Database: NoDb, Router: GIN}
+	if err := Write(s, m); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := fstest.TestFS(m, "go.mod", "cmd/orders/main.go", ProjectManifestFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fs.Stat(m, "cmd/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("stat of missing directory: %v, want fs.ErrNotExist", err)
+	}
+	if _, err := m.Open("/go.mod"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("open of absolute path: %v, want fs.ErrInvalid", err)
+	}
+}
+
+func TestMemFSEmpty(t *testing.T) {
+	if err := fstest.TestFS(MemFS{}); err != nil {
+		t.Fatal(err)
+	}
+}
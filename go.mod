@@ -3,7 +3,6 @@ module github.com/rtsoftSG/skeleton
 go 1.16
 
 require (
-	github.com/dixonwille/wlog/v3 v3.0.1
-	github.com/dixonwille/wmenu/v5 v5.1.0
 	github.com/urfave/cli/v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
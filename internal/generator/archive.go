@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveWriter adds files and directories of the project to the archive.
+type archiveWriter interface {
+	add(name string, info fs.FileInfo, content io.Reader) error
+	Close() error
+}
+
+// CheckArchivePath checks that the archive format is known by the file extension: .zip, .tar.gz or .tgz.
+func CheckArchivePath(name string) error {
+	if _, ok := archiveFormat(name); !ok {
+		return &FieldError{Field: "output archive", Value: name, Reason: "expected .zip, .tar.gz or .tgz file"}
+	}
+	return nil
+}
+
+func archiveFormat(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip", true
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz", true
+	default:
+		return "", false
+	}
+}
+
+// WriteArchive packs the directory tree rootDir into the archive under the directory with the base name of rootDir,
+// the same layout WriteZip uses. The archive is written to a temporary file first and replaces name on success.
+func WriteArchive(name, rootDir string) (err error) {
+	format, ok := archiveFormat(name)
+	if !ok {
+		return CheckArchivePath(name)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	var aw archiveWriter
+	switch format {
+	case "zip":
+		aw = &zipWriter{w: zip.NewWriter(f)}
+	default:
+		gz := gzip.NewWriter(f)
+		aw = &tarGzWriter{gz: gz, w: tar.NewWriter(gz)}
+	}
+
+	parent := filepath.Dir(rootDir)
+	err = filepath.WalkDir(rootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(parent, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return aw.add(filepath.ToSlash(rel), info, nil)
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", p)
+		}
+
+		content, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		return aw.add(filepath.ToSlash(rel), info, content)
+	})
+	if err != nil {
+		return err
+	}
+	if err = aw.Close(); err != nil {
+		return err
+	}
+	// the temporary file is created with 0600 mode.
+	if err = f.Chmod(0644); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+type zipWriter struct {
+	w *zip.Writer
+}
+
+func (z *zipWriter) add(name string, info fs.FileInfo, content io.Reader) error {
+	h, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	h.Name = name
+	if info.IsDir() {
+		h.Name += "/"
+	} else {
+		h.Method = zip.Deflate
+	}
+
+	w, err := z.w.CreateHeader(h)
+	if err != nil || content == nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	return z.w.Close()
+}
+
+type tarGzWriter struct {
+	gz *gzip.Writer
+	w  *tar.Writer
+}
+
+func (t *tarGzWriter) add(name string, info fs.FileInfo, content io.Reader) error {
+	h, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	h.Name = name
+	if info.IsDir() {
+		h.Name += "/"
+	}
+	// owner of the files on the generating machine means nothing for the archive receiver.
+	h.Uid, h.Gid, h.Uname, h.Gname = 0, 0, "", ""
+
+	if err = t.w.WriteHeader(h); err != nil || content == nil {
+		return err
+	}
+	_, err = io.Copy(t.w, content)
+	return err
+}
+
+func (t *tarGzWriter) Close() error {
+	if err := t.w.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
+
+// WriteZip writes files rendered in memory as zip archive into w, files are placed under prefix directory
+// with the same modes Run gives them, as WriteArchive does.
+func WriteZip(w io.Writer, prefix string, files []File) error {
+	sorted := append([]File(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	dirs := map[string]bool{".": true, "/": true}
+	var addDir func(dir string) error
+	addDir = func(dir string) error {
+		if dirs[dir] {
+			return nil
+		}
+		if err := addDir(path.Dir(dir)); err != nil {
+			return err
+		}
+		dirs[dir] = true
+
+		h := &zip.FileHeader{Name: dir + "/", Modified: now}
+		h.SetMode(fs.ModeDir | 0755)
+		_, err := zw.CreateHeader(h)
+		return err
+	}
+
+	for _, f := range sorted {
+		name := path.Join(prefix, f.Path)
+		if err := addDir(path.Dir(name)); err != nil {
+			return err
+		}
+
+		h := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now}
+		h.SetMode(0644)
+		fw, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(f.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
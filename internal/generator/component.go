@@ -0,0 +1,307 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/merge"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Component is an optional part of the project which may be added after generation.
+type Component string
+
+const (
+	ConsulComponent       Component = "consul"
+	ConsulConfigComponent Component = "consul-config"
+	JaegerComponent       Component = "jaeger"
+	PrometheusComponent   Component = "prometheus"
+	PostgresComponent     Component = "postgres"
+	ClickhouseComponent   Component = "clickhouse"
+)
+
+// Components lists all optional components.
+var Components = []Component{
+	ConsulComponent,
+	ConsulConfigComponent,
+	JaegerComponent,
+	PrometheusComponent,
+	PostgresComponent,
+	ClickhouseComponent,
+}
+
+// ParseComponent converts command line value to Component.
+func ParseComponent(v string) (Component, error) {
+	for _, c := range Components {
+		if string(c) == strings.ToLower(v) {
+			return c, nil
+		}
+	}
+
+	names := make([]string, 0, len(Components))
+	for _, c := range Components {
+		names = append(names, string(c))
+	}
+	return "", fmt.Errorf("unknown component %q, expected one of: %s", v, strings.Join(names, ", "))
+}
+
+// enabled reports whether the project generated with s has the component.
+func (c Component) enabled(s *Settings) bool {
+	switch c {
+	case ConsulComponent:
+		return s.UseConsul
+	case ConsulConfigComponent:
+		return s.SyncConfigWithConsul
+	case JaegerComponent:
+		return s.UseJaeger
+	case PrometheusComponent:
+		return s.UsePrometheus
+	case PostgresComponent:
+		return s.Database == Postgresql
+	case ClickhouseComponent:
+		return s.Database == Clickhouse
+	default:
+		return false
+	}
+}
+
+// enable returns settings override which adds the component to the project generated with s.
+func (c Component) enable(s *Settings) (*Manifest, error) {
+	if c.enabled(s) {
+		return nil, fmt.Errorf("project already has %s", c)
+	}
+
+	switch c {
+	case ConsulComponent:
+		return &Manifest{UseConsul: boolPtr(true)}, nil
+	case ConsulConfigComponent:
+		if !s.UseConsul {
+			return nil, fmt.Errorf("%s requires %s, add it first", c, ConsulComponent)
+		}
+		return &Manifest{SyncConfigWithConsul: boolPtr(true)}, nil
+	case JaegerComponent:
+		return &Manifest{UseJaeger: boolPtr(true)}, nil
+	case PrometheusComponent:
+		return &Manifest{UsePrometheus: boolPtr(true)}, nil
+	case PostgresComponent:
+		return enableDatabase(s, Postgresql)
+	case ClickhouseComponent:
+		return enableDatabase(s, Clickhouse)
+	default:
+		return nil, fmt.Errorf("unknown component %q", c)
+	}
+}
+
+func enableDatabase(s *Settings, db DBChoice) (*Manifest, error) {
+	if s.Database != NoDb {
+		return nil, fmt.Errorf("project already uses %s database", s.Database)
+	}
+	return &Manifest{Database: db}, nil
+}
+
+// disable returns settings override which removes the component from the project generated with s.
+func (c Component) disable(s *Settings) (*Manifest, error) {
+	if !c.enabled(s) {
+		return nil, fmt.Errorf("project has no %s", c)
+	}
+
+	switch c {
+	case ConsulComponent:
+		// configuration can't be synced without consul.
+		return &Manifest{UseConsul: boolPtr(false), SyncConfigWithConsul: boolPtr(false)}, nil
+	case ConsulConfigComponent:
+		return &Manifest{SyncConfigWithConsul: boolPtr(false)}, nil
+	case JaegerComponent:
+		return &Manifest{UseJaeger: boolPtr(false)}, nil
+	case PrometheusComponent:
+		return &Manifest{UsePrometheus: boolPtr(false)}, nil
+	case PostgresComponent, ClickhouseComponent:
+		return &Manifest{Database: NoDb}, nil
+	default:
+		return nil, fmt.Errorf("unknown component %q", c)
+	}
+}
+
+// Add adds the component to the project in rootDir: changes made by the component to the rendered files
+// are merged with the current files the same way Upgrade does, templates aren't upgraded.
+func Add(ctx context.Context, rootDir string, c Component) (*UpgradeReport, error) {
+	return changeComponent(ctx, rootDir, "with "+string(c), c.enable, nil)
+}
+
+// Remove strips the component from the project in rootDir: its package is deleted unless it was edited by hand,
+// config fields, config.yml keys, wiring and README lines are merged out of the current files.
+func Remove(ctx context.Context, rootDir string, c Component) (*UpgradeReport, error) {
+	return changeComponent(ctx, rootDir, "without "+string(c), c.disable, c.unwrap)
+}
+
+// wrappers returns functions wrapping endpoints and handlers with the component, code added by hand
+// (e.g. by add endpoint) uses them the same way as generated one.
+func (c Component) wrappers() []string {
+	if c == JaegerComponent {
+		return []string{"TraceLoggerMiddleware", "kitopentracing.TraceServer"}
+	}
+	return nil
+}
+
+// unwrap removes wrapping with the component from go files, so the code using it builds after the component is removed.
+func (c Component) unwrap(path string, content []byte) []byte {
+	wrappers := c.wrappers()
+	if len(wrappers) == 0 || !strings.HasSuffix(path, ".go") {
+		return content
+	}
+
+	res, err := unwrapCalls(path, content, wrappers)
+	if err != nil {
+		// file which isn't valid go is merged as is.
+		return content
+	}
+	return res
+}
+
+// changeComponent applies settings change to the project. Files of the project are rendered by the current
+// templates with settings before and after the change, the difference is merged into snapshots of the generated
+// files, so the project gets only the component change and keeps the skeleton version it was generated with.
+func changeComponent(ctx context.Context, rootDir, label string, change func(s *Settings) (*Manifest, error), rewrite rewriteFunc) (*UpgradeReport, error) {
+	if err := finishPending(ctx, rootDir); err != nil {
+		return nil, err
+	}
+	pm, err := ReadProjectManifest(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	old := &Settings{ProjectRootDir: rootDir}
+	pm.Settings.Apply(old)
+	override, err := change(old)
+	if err != nil {
+		return nil, err
+	}
+	settings := &Settings{ProjectRootDir: rootDir}
+	pm.Settings.Apply(settings)
+	override.Apply(settings)
+	raisedFrom := raiseGoVersion(settings)
+	if err = settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	from, err := Render(old)
+	if err != nil {
+		return nil, err
+	}
+	to, err := Render(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UpgradeReport{FromVersion: pm.Version, ToVersion: SkeletonVersion(), RaisedGoVersion: raisedFrom}
+	files, err := componentFiles(rootDir, pm, from, to, report)
+	if err != nil {
+		return nil, err
+	}
+
+	version := pm.Version
+	if len(report.Upgraded) != 0 {
+		// snapshots of upgraded files come from the current templates.
+		version = report.ToVersion
+	} else {
+		report.ToVersion = pm.Version
+	}
+	mf, err := versionManifestFiles(version, settings, files)
+	if err != nil {
+		return nil, fmt.Errorf("render project manifest: %w", err)
+	}
+
+	fromFiles := make(map[string][]byte, len(from))
+	for _, f := range from {
+		fromFiles[f.Path] = f.Content
+	}
+	labels := merge.Labels{
+		Ours:   "current",
+		Base:   "skeleton " + pm.Version,
+		Theirs: "skeleton " + pm.Version + " " + label,
+	}
+	return report, mergeProject(ctx, rootDir, pm, files, fromFiles, mf, labels, rewrite, report)
+}
+
+// componentFiles returns files of the project as templates it was generated with would render them after
+// the component change: difference of from and to, rendered by the current templates before and after the change,
+// is applied to snapshots of the generated files. Files without snapshots and files where the change overlaps
+// template changes are taken from to and listed in report.Upgraded.
+func componentFiles(rootDir string, pm *ProjectManifest, from, to []File, report *UpgradeReport) ([]File, error) {
+	fromFiles := make(map[string][]byte, len(from))
+	for _, f := range from {
+		fromFiles[f.Path] = f.Content
+	}
+
+	files := make([]File, 0, len(to))
+	rendered := make(map[string]bool, len(to))
+	for _, f := range to {
+		rendered[f.Path] = true
+
+		before, ok := fromFiles[f.Path]
+		if !ok {
+			files = append(files, f)
+			continue
+		}
+
+		snapshot, ok, err := generatedContent(rootDir, pm, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok && pm.Checksum(f.Path) == checksum(before) {
+			snapshot, ok = before, true
+		}
+
+		if ok {
+			merged, conflicts := merge.ThreeWay(before, snapshot, f.Content, merge.Labels{})
+			if conflicts == 0 {
+				files = append(files, File{Path: f.Path, Content: merged})
+				continue
+			}
+		}
+		if !bytes.Equal(before, f.Content) || !ok {
+			report.Upgraded = append(report.Upgraded, f.Path)
+		}
+		files = append(files, f)
+	}
+
+	// files generated by older templates stay as they are.
+	for _, old := range pm.Files {
+		if rendered[old.Path] {
+			continue
+		}
+		if _, ok := fromFiles[old.Path]; ok {
+			continue
+		}
+
+		snapshot, ok, err := generatedContent(rootDir, pm, old.Path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			files = append(files, File{Path: old.Path, Content: snapshot})
+		}
+	}
+
+	return files, nil
+}
+
+// generatedContent returns the file as it was generated: its snapshot or the file itself if it isn't edited.
+func generatedContent(rootDir string, pm *ProjectManifest, path string) ([]byte, bool, error) {
+	snapshot, ok, err := readBaseSnapshot(rootDir, path)
+	if ok || err != nil {
+		return snapshot, ok, err
+	}
+
+	current, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	recorded := pm.Checksum(path)
+	return current, recorded != "" && checksum(current) == recorded, nil
+}
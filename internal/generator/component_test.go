@@ -0,0 +1,257 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRemoveBuilds generates projects with all components, removes each of them and builds the result.
+// It needs the go toolchain and dependencies of generated projects in the module cache or from the proxy.
+func TestRemoveBuilds(t *testing.T) {
+	skipBuilds(t)
+
+	projects := []Settings{
+		{Logger: GoKit, Database: Postgresql, Router: GorillaMux},
+		{Logger: Zap, Database: Clickhouse, Router: GorillaMux},
+		{Logger: GoKit, Database: Clickhouse, Router: GIN},
+		{Logger: Zap, Database: Postgresql, Router: GIN},
+	}
+	for _, p := range projects {
+		for _, c := range Components {
+			p, c := p, c
+			p.UseConsul, p.SyncConfigWithConsul, p.UseJaeger, p.UsePrometheus = true, true, true, true
+			if !c.enabled(&p) {
+				continue
+			}
+
+			t.Run(fmt.Sprintf("%s-%s-%s-without-%s", p.Router, p.Logger, p.Database, c), func(t *testing.T) {
+				t.Parallel()
+
+				dir := t.TempDir()
+				p.ProjectName = "svc"
+				p.ProjectRootDir = dir
+				p.Reporter = discardReporter{}
+				if _, err := Run(context.Background(), &p); err != nil {
+					t.Fatalf("generate: %v", err)
+				}
+
+				report, err := Remove(context.Background(), dir, c)
+				if err != nil {
+					t.Fatalf("remove: %v", err)
+				}
+				if conflicts := report.Conflicts(); len(conflicts) != 0 {
+					t.Fatalf("remove left conflicts: %v", conflicts)
+				}
+
+				goCommands(t, dir, []string{"go", "mod", "tidy"}, []string{"go", "build", "./..."})
+			})
+		}
+	}
+}
+
+// skipBuilds skips tests building generated projects in short mode or without go toolchain.
+func skipBuilds(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds generated projects")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain isn't found")
+	}
+}
+
+// goCommands runs go commands in the project dir, dependencies are resolved without vendoring.
+func goCommands(t *testing.T, dir string, commands ...[]string) {
+	t.Helper()
+	for _, args := range commands {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+// TestAddBuilds generates projects without components, adds each of them and builds the result.
+func TestAddBuilds(t *testing.T) {
+	skipBuilds(t)
+
+	projects := []Settings{
+		{Logger: GoKit, Database: NoDb, Router: GorillaMux},
+		{Logger: Zap, Database: NoDb, Router: GIN},
+	}
+	for _, p := range projects {
+		for _, c := range Components {
+			p, c := p, c
+			// consul-config needs consul, it's the only component which requires another one.
+			p.UseConsul = c == ConsulConfigComponent
+
+			t.Run(fmt.Sprintf("%s-%s-with-%s", p.Router, p.Logger, c), func(t *testing.T) {
+				t.Parallel()
+
+				dir := t.TempDir()
+				p.ProjectName = "svc"
+				p.ProjectRootDir = dir
+				p.Reporter = discardReporter{}
+				if _, err := Run(context.Background(), &p); err != nil {
+					t.Fatalf("generate: %v", err)
+				}
+
+				report, err := Add(context.Background(), dir, c)
+				if err != nil {
+					t.Fatalf("add: %v", err)
+				}
+				if conflicts := report.Conflicts(); len(conflicts) != 0 {
+					t.Fatalf("add left conflicts: %v", conflicts)
+				}
+				checkProjectManifest(t, dir, c)
+
+				goCommands(t, dir, []string{"go", "mod", "tidy"}, []string{"go", "build", "./..."})
+			})
+		}
+	}
+}
+
+// TestAddConflict adds a component to the project with a hand edit of the lines the component changes:
+// the conflict is left with diff3 markers and the new manifest waits in the pending directory
+// until the markers are resolved.
+func TestAddConflict(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := &Settings{ProjectName: "svc", ProjectRootDir: dir, Logger: Zap, Database: NoDb, Router: GIN,
+		Reporter: discardReporter{}}
+	if _, err := Run(ctx, s); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// the edit replaces the lines around the first change made by prometheus.
+	with := *s
+	with.UsePrometheus = true
+	path, edited, resolved := overlappingEdit(t, s, &with)
+	writeProjectFile(t, dir, path, edited)
+	manifest := readProjectFile(t, dir, ProjectManifestFile)
+
+	report, err := Add(ctx, dir, PrometheusComponent)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	conflicts := report.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Path != path {
+		t.Fatalf("conflicts = %v, want %s", conflicts, path)
+	}
+	content := readProjectFile(t, dir, path)
+	for _, marker := range []string{"<<<<<<< current", "||||||| ", "=======", ">>>>>>> "} {
+		if !strings.Contains(content, marker) {
+			t.Errorf("%s has no %q marker:\n%s", path, marker, content)
+		}
+	}
+	if readProjectFile(t, dir, ProjectManifestFile) != manifest {
+		t.Errorf("%s is changed while conflicts remain", ProjectManifestFile)
+	}
+	if !strings.Contains(readProjectFile(t, dir, PendingDir+"/"+ProjectManifestFile), "prometheus: true") {
+		t.Errorf("pending %s doesn't have prometheus", ProjectManifestFile)
+	}
+
+	// the next change refuses to start while markers remain.
+	if _, err = Add(ctx, dir, JaegerComponent); err == nil {
+		t.Fatal("add with unresolved conflicts succeeded")
+	}
+
+	writeProjectFile(t, dir, path, resolved)
+	report, err = Add(ctx, dir, JaegerComponent)
+	if err != nil {
+		t.Fatalf("add after resolving: %v", err)
+	}
+	if conflicts := report.Conflicts(); len(conflicts) != 0 {
+		t.Fatalf("add after resolving left conflicts: %v", conflicts)
+	}
+	if _, err = os.Stat(filepath.Join(dir, filepath.FromSlash(PendingDir))); !os.IsNotExist(err) {
+		t.Errorf("pending directory is left: %v", err)
+	}
+	checkProjectManifest(t, dir, PrometheusComponent, JaegerComponent)
+}
+
+// overlappingEdit renders the project with settings from and to and returns the first file they differ in,
+// its from version edited around the first difference and its to version with the edit comment appended.
+func overlappingEdit(t *testing.T, from, to *Settings) (path, edited, resolved string) {
+	t.Helper()
+	fromFiles, err := Render(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toFiles, err := Render(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := map[string]string{}
+	for _, f := range toFiles {
+		rendered[f.Path] = string(f.Content)
+	}
+
+	for _, f := range fromFiles {
+		if to, ok := rendered[f.Path]; ok && to != string(f.Content) && strings.HasSuffix(f.Path, ".go") {
+			a, b := strings.SplitAfter(string(f.Content), "\n"), strings.SplitAfter(to, "\n")
+			i := 0
+			for i < len(a) && i < len(b) && a[i] == b[i] {
+				i++
+			}
+			if i == 0 || i >= len(a) {
+				continue
+			}
+			// lines i-1 and i are replaced, so the change of to at line i overlaps the edit.
+			edit := "// hand edit\n"
+			edited = strings.Join(a[:i-1], "") + edit + strings.Join(a[i+1:], "")
+			return f.Path, edited, to + edit
+		}
+	}
+	t.Fatal("rendered projects have no overlapping changes")
+	return "", "", ""
+}
+
+// checkProjectManifest checks that .skeleton.yml has the components, its checksums and snapshots
+// match the files which aren't edited and there is no pending change.
+func checkProjectManifest(t *testing.T, dir string, components ...Component) {
+	t.Helper()
+	pm, err := ReadProjectManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Settings{}
+	pm.Settings.Apply(s)
+	for _, c := range components {
+		if !c.enabled(s) {
+			t.Errorf("%s doesn't have %s", ProjectManifestFile, c)
+		}
+	}
+
+	for _, f := range pm.Files {
+		content := readProjectFile(t, dir, f.Path)
+		if checksum([]byte(content)) != f.SHA256 {
+			// the only files which may differ are the ones edited by the test.
+			if !strings.Contains(content, "// hand edit\n") {
+				t.Errorf("checksum of %s doesn't match", f.Path)
+			}
+			continue
+		}
+		snapshot, ok, err := readBaseSnapshot(dir, f.Path)
+		if err != nil || !ok || string(snapshot) != content {
+			t.Errorf("snapshot of %s doesn't match the file, exists %v, error %v", f.Path, ok, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, filepath.FromSlash(PendingDir))); !os.IsNotExist(err) {
+		t.Errorf("pending directory exists: %v", err)
+	}
+}
+
+func writeProjectFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(path)), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
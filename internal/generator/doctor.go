@@ -0,0 +1,288 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CheckStatus is the outcome of the doctor check.
+type CheckStatus string
+
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// Check is the result of the doctor check of a tool or a setting.
+type Check struct {
+	Name   string
+	Status CheckStatus
+	// Detail is the found version or value.
+	Detail string
+	// Fix suggests how to fix failed or warned check.
+	Fix string
+}
+
+// toolTimeout limits every command run by Doctor, docker may hang on unreachable daemon.
+const toolTimeout = 10 * time.Second
+
+// Doctor checks the tools and go environment used by the workflow of the generated project:
+// go builds it, golangci-lint and swag are run by make lint and make swag, docker builds the image,
+// go mod vendor needs modules from the proxy or the module cache.
+// goVersion is the go version of the projects, version of the toolchain is used if it's empty.
+func Doctor(goVersion string) []Check {
+	goCheck, toolchain := checkGo()
+	checks := []Check{goCheck}
+	if toolchain != "" {
+		checks = append(checks, checkProjectGoVersion(toolchain, goVersion))
+		checks = append(checks, checkGoEnv()...)
+	}
+
+	return append(checks,
+		checkGolangciLint(),
+		checkTool("swag", "make swag", "go install github.com/swaggo/swag/cmd/swag@latest", "--version"),
+		checkDocker(),
+	)
+}
+
+func checkGo() (Check, string) {
+	c := Check{Name: "go"}
+	out, err := runTool("go", "env", "GOVERSION")
+	if err != nil {
+		c.Status, c.Detail = CheckFail, err.Error()
+		c.Fix = "install go from https://go.dev/dl/ and add its bin directory to PATH"
+		return c, ""
+	}
+
+	c.Status, c.Detail = CheckOK, out
+	toolchain := strings.TrimPrefix(out, "go")
+	if err = checkGoVersion(toolchain); err != nil {
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("%s, projects need at least go %s", out, MinGoVersion)
+		c.Fix = "install newer go from https://go.dev/dl/"
+	}
+	return c, toolchain
+}
+
+func checkProjectGoVersion(toolchain, goVersion string) Check {
+	c := Check{Name: "go version", Status: CheckOK}
+	if goVersion == "" {
+		minor, _ := parseGoVersion(toolchain)
+		c.Detail = fmt.Sprintf("projects use go 1.%d of the toolchain", minor)
+		return c
+	}
+
+	c.Detail = "projects use go " + goVersion
+	if err := checkGoVersion(goVersion); err != nil {
+		c.Status, c.Detail = CheckFail, err.Error()
+		return c
+	}
+	want, _ := parseGoVersion(goVersion)
+	if goVersionAtLeast(toolchain, want) {
+		return c
+	}
+
+	// since go 1.21 the go command downloads toolchain required by go.mod unless GOTOOLCHAIN=local.
+	if goToolchain, _ := runTool("go", "env", "GOTOOLCHAIN"); goVersionAtLeast(toolchain, 21) && goToolchain != "local" {
+		c.Status = CheckWarn
+		c.Detail += fmt.Sprintf(", toolchain %s will download go %s on the first build", toolchain, goVersion)
+		c.Fix = "install go " + goVersion + " to build offline"
+		return c
+	}
+	c.Status = CheckFail
+	c.Detail += ", but toolchain is " + toolchain
+	c.Fix = fmt.Sprintf("install go %s or generate projects with --go-version %s", goVersion, DetectGoVersion())
+	return c
+}
+
+// goEnv is the part of go env used by the checks.
+type goEnv struct {
+	GOPROXY    string
+	GOFLAGS    string
+	GOMODCACHE string
+	GOSUMDB    string
+	GONOSUMDB  string
+	GOPRIVATE  string
+}
+
+// checkGoEnv checks that go mod vendor can get modules: from the proxy, VCS or the module cache when offline.
+func checkGoEnv() []Check {
+	out, err := runTool("go", "env", "-json", "GOPROXY", "GOFLAGS", "GOMODCACHE", "GOSUMDB", "GONOSUMDB", "GOPRIVATE")
+	if err != nil {
+		return []Check{{Name: "go env", Status: CheckFail, Detail: err.Error()}}
+	}
+	var env goEnv
+	if err = json.Unmarshal([]byte(out), &env); err != nil {
+		return []Check{{Name: "go env", Status: CheckFail, Detail: err.Error()}}
+	}
+
+	cache, cached := checkModCache(env.GOMODCACHE)
+	return []Check{checkGoProxy(env, cached), checkGoFlags(env.GOFLAGS), cache, checkGoSumDB(env)}
+}
+
+func checkGoProxy(env goEnv, cached int) Check {
+	c := Check{Name: "GOPROXY", Status: CheckOK, Detail: env.GOPROXY}
+
+	var proxies []string
+	direct := false
+	for _, p := range strings.FieldsFunc(env.GOPROXY, func(r rune) bool { return r == ',' || r == '|' }) {
+		switch p {
+		case "off":
+		case "direct":
+			direct = true
+		default:
+			proxies = append(proxies, p)
+		}
+	}
+
+	switch {
+	case env.GOPROXY == "off" && cached == 0:
+		c.Status = CheckFail
+		c.Detail += ", downloads are disabled and the module cache is empty, go mod vendor can't get dependencies"
+		c.Fix = "fill the module cache online once (e.g. skeleton generate with GOPROXY=https://proxy.golang.org) " +
+			"or set GOPROXY to the company proxy"
+	case env.GOPROXY == "off":
+		c.Status = CheckWarn
+		c.Detail += ", dependencies are taken only from the module cache"
+		c.Fix = "dependencies missing from the cache fail go mod vendor, fill the cache online or set GOPROXY"
+	case len(proxies) == 0 && direct:
+		c.Detail += ", modules are downloaded from version control"
+		if _, err := exec.LookPath("git"); err != nil {
+			c.Status = CheckFail
+			c.Fix = "install git or set GOPROXY=https://proxy.golang.org,direct"
+		}
+	}
+	return c
+}
+
+func checkGoFlags(flags string) Check {
+	c := Check{Name: "GOFLAGS", Status: CheckOK, Detail: flags}
+	if flags == "" {
+		c.Detail = "not set"
+	}
+	for _, f := range strings.Fields(flags) {
+		if f == "-mod=vendor" {
+			c.Status = CheckWarn
+			c.Detail += ", builds use only vendor directory"
+			c.Fix = "keep --with-dependencies on, projects generated without vendor directory don't build"
+		}
+	}
+	return c
+}
+
+// checkModCache checks that the module cache is writable and returns the number of cached modules.
+func checkModCache(dir string) (Check, int) {
+	c := Check{Name: "module cache", Status: CheckOK}
+	if dir == "" {
+		c.Status, c.Detail = CheckFail, "GOMODCACHE is empty"
+		c.Fix = "set GOPATH or GOMODCACHE"
+		return c, 0
+	}
+
+	cached := countCachedModules(filepath.Join(dir, "cache", "download"))
+	c.Detail = fmt.Sprintf("%s, %d modules", dir, cached)
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		c.Detail = dir + ", not created yet"
+		return c, 0
+	}
+	f, err := os.CreateTemp(dir, ".skeleton-doctor-")
+	if err != nil {
+		c.Status, c.Fix = CheckFail, "make "+dir+" writable or set GOMODCACHE to writable directory"
+		return c, cached
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return c, cached
+}
+
+// countCachedModules counts module versions downloaded into the cache, every version has .zip in @v directory.
+func countCachedModules(downloadDir string) int {
+	n := 0
+	_ = filepath.Walk(downloadDir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(p, ".zip") && filepath.Base(filepath.Dir(p)) == "@v" {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+func checkGoSumDB(env goEnv) Check {
+	c := Check{Name: "GOSUMDB", Status: CheckOK, Detail: env.GOSUMDB}
+	if env.GOSUMDB == "off" || env.GOPROXY != "off" {
+		return c
+	}
+	c.Status = CheckWarn
+	c.Detail += ", checksums of modules missing from go.sum are looked up online"
+	c.Fix = "set GOSUMDB=off or GONOSUMDB for private modules to vendor fully offline"
+	return c
+}
+
+func checkGolangciLint() Check {
+	c := checkTool("golangci-lint", "make lint",
+		"go install github.com/golangci/golangci-lint/cmd/golangci-lint@v1.64.8", "--version")
+	// generated .golangci.yml uses v1 configuration format which v2 rejects.
+	if c.Status == CheckOK && regexp.MustCompile(`version v?2\.`).MatchString(c.Detail) {
+		c.Status = CheckWarn
+		c.Fix = "generated .golangci.yml has v1 format, run golangci-lint migrate in the project or install v1"
+	}
+	return c
+}
+
+func checkDocker() Check {
+	c := checkTool("docker", "the image build", "install docker from https://docs.docker.com/get-docker/", "--version")
+	if c.Status != CheckOK {
+		return c
+	}
+	if _, err := runTool("docker", "version", "--format", "{{.Server.Version}}"); err != nil {
+		c.Status = CheckWarn
+		c.Detail += ", daemon is not reachable"
+		c.Fix = "start docker daemon and check that the user may access it"
+	}
+	return c
+}
+
+// checkTool checks that the tool is in PATH and returns its version, missing tool is a warning
+// because only the part of the workflow (usage) needs it.
+func checkTool(name, usage, fix string, versionArgs ...string) Check {
+	c := Check{Name: name}
+	out, err := runTool(name, versionArgs...)
+	if err != nil {
+		c.Status, c.Detail = CheckWarn, err.Error()+", needed by "+usage
+		c.Fix = fix
+		return c
+	}
+	c.Status, c.Detail = CheckOK, firstLine(out)
+	return c
+}
+
+// runTool runs the command with timeout and returns its output.
+func runTool(name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s is not found in PATH", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %v", name, strings.Join(args, " "), err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
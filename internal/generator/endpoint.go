@@ -0,0 +1,492 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HTTPMethods lists methods of endpoints which may be added to the project.
+var HTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// Endpoint is an HTTP API endpoint added to the generated project.
+type Endpoint struct {
+	// Name is the endpoint name, e.g. create-order or CreateOrder, it becomes CreateOrderRequest, MakeCreateOrderEndpoint etc.
+	Name   string
+	Method string
+	Path   string
+}
+
+// Validate checks the endpoint fields.
+func (e *Endpoint) Validate() error {
+	if _, err := endpointName(e.Name); err != nil {
+		return err
+	}
+
+	method := strings.ToUpper(e.Method)
+	valid := false
+	for _, m := range HTTPMethods {
+		valid = valid || m == method
+	}
+	if !valid {
+		return &FieldError{Field: "method", Value: e.Method, Reason: "expected one of: " + strings.Join(HTTPMethods, ", ")}
+	}
+
+	if !strings.HasPrefix(e.Path, "/") {
+		return &FieldError{Field: "path", Value: e.Path, Reason: "must start with /"}
+	}
+	if strings.ContainsAny(e.Path, "\"\\` \t\n?#") {
+		return &FieldError{Field: "path", Value: e.Path, Reason: "must not contain quotes, spaces, query or fragment"}
+	}
+
+	return nil
+}
+
+// endpointName converts the name to exported go identifier, e.g. create-order becomes CreateOrder.
+func endpointName(v string) (string, error) {
+	parts := strings.FieldsFunc(v, func(r rune) bool { return r == '-' || r == '_' || r == ' ' })
+
+	var b strings.Builder
+	for _, p := range parts {
+		runes := []rune(p)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+
+	if name == "" {
+		return "", &FieldError{Field: "name", Value: v, Reason: "must not be empty"}
+	}
+	for i, r := range name {
+		if !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return "", &FieldError{Field: "name", Value: v, Reason: "must be letters and digits starting with a letter"}
+		}
+	}
+	return name, nil
+}
+
+// endpointData is the data of endpoint_snippets template.
+type endpointData struct {
+	// Name is exported name of the endpoint, Var is the same name for local variables.
+	Name string
+	Var  string
+
+	Method string
+	// Path is relative to the router (or gin route group) the endpoint is registered in, FullPath is the request path.
+	Path     string
+	FullPath string
+	HasBody  bool
+
+	// Router is the variable of the router the route is registered in.
+	Router string
+	// Logger is the variable of the logger in NewServer.
+	Logger string
+	// Service is the service name used by tracing.
+	Service string
+
+	UseJaeger      bool
+	UseGoKitLogger bool
+	UseZapLogger   bool
+}
+
+// AddEndpoint adds request and response types, handling code, route registration and a test of the endpoint
+// to the project in rootDir for the router the project uses. Existing files are edited at positions found
+// in their syntax trees, so hand edits are kept. All files are changed at once through the staging,
+// on any error or cancellation of ctx the project is left as it was. It returns paths of changed files.
+func AddEndpoint(ctx context.Context, rootDir string, e Endpoint) ([]string, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	pm, err := ReadProjectManifest(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{ProjectRootDir: rootDir}
+	pm.Settings.Apply(s)
+
+	name, _ := endpointName(e.Name)
+	first, size := utf8.DecodeRuneInString(name)
+	method := strings.ToUpper(e.Method)
+	data := &endpointData{
+		Name:           name,
+		Var:            string(unicode.ToLower(first)) + name[size:],
+		Method:         method,
+		Path:           e.Path,
+		FullPath:       e.Path,
+		HasBody:        method == "POST" || method == "PUT" || method == "PATCH",
+		Service:        s.ProjectName,
+		UseJaeger:      s.UseJaeger,
+		UseGoKitLogger: s.Logger == GoKit,
+		UseZapLogger:   s.Logger == Zap,
+	}
+
+	g := generator{settings: s}
+	tpl, err := g.createTemplate("endpoint_snippets")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*goFile
+	switch s.Router {
+	case GorillaMux:
+		files, err = addGoKitEndpoint(rootDir, tpl, data)
+	case GIN:
+		files, err = addGinEndpoint(rootDir, tpl, data)
+	default:
+		err = fmt.Errorf("unknown router %s", s.Router)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	test, err := addEndpointTest(rootDir, tpl, data)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, test)
+
+	changes := make([]File, len(files))
+	paths := make([]string, len(files))
+	for i, f := range files {
+		content, err := f.apply()
+		if err != nil {
+			return nil, err
+		}
+		changes[i], paths[i] = File{Path: f.path, Content: content}, f.path
+	}
+	if err = writeChanges(ctx, rootDir, changes, nil); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+func execSnippet(tpl *template.Template, name string, data *endpointData) (string, error) {
+	out := &bytes.Buffer{}
+	if err := tpl.ExecuteTemplate(out, name, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// addGoKitEndpoint adds the endpoint to go-kit endpoints and gorilla/mux http server.
+func addGoKitEndpoint(rootDir string, tpl *template.Template, d *endpointData) ([]*goFile, error) {
+	ep, err := parseGoFile(rootDir, "internal/endpoint/endpoints.go")
+	if err != nil {
+		return nil, err
+	}
+	if ep.hasDecl(d.Name+"Request") || ep.hasDecl("Make"+d.Name+"Endpoint") {
+		return nil, fmt.Errorf("endpoint %s already exists in %s", d.Name, ep.path)
+	}
+
+	ts := ep.typeSpec("Endpoints")
+	if ts == nil {
+		return nil, fmt.Errorf("%s: type Endpoints not found", ep.path)
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%s: Endpoints is not a struct", ep.path)
+	}
+	field, err := execSnippet(tpl, "gokit_field", d)
+	if err != nil {
+		return nil, err
+	}
+	ep.insertItem(st.Fields.Closing, lastField(st.Fields.List), "", strings.TrimSpace(field))
+
+	fn := ep.funcDecl("NewEndpoints")
+	if fn == nil {
+		return nil, fmt.Errorf("%s: func NewEndpoints not found", ep.path)
+	}
+	var (
+		varDecl *ast.GenDecl
+		lit     *ast.CompositeLit
+	)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GenDecl:
+			if n.Tok == token.VAR && n.Lparen.IsValid() && varDecl == nil {
+				varDecl = n
+			}
+		case *ast.CompositeLit:
+			if id, ok := n.Type.(*ast.Ident); ok && id.Name == "Endpoints" {
+				lit = n
+			}
+		}
+		return true
+	})
+	if lit == nil {
+		return nil, fmt.Errorf("%s: NewEndpoints doesn't build Endpoints", ep.path)
+	}
+
+	value := d.Var + "Endpoint"
+	if varDecl != nil {
+		spec, err := execSnippet(tpl, "gokit_var", d)
+		if err != nil {
+			return nil, err
+		}
+		var last ast.Node
+		if len(varDecl.Specs) != 0 {
+			last = varDecl.Specs[len(varDecl.Specs)-1]
+		}
+		ep.insertItem(varDecl.Rparen, last, "", strings.TrimSpace(spec))
+	} else if value, err = execSnippet(tpl, "gokit_value", d); err != nil {
+		return nil, err
+	}
+	var last ast.Node
+	if len(lit.Elts) != 0 {
+		last = lit.Elts[len(lit.Elts)-1]
+	}
+	ep.insertItem(lit.Rbrace, last, ",", d.Name+"Endpoint: "+value)
+
+	code, err := execSnippet(tpl, "gokit_endpoint", d)
+	if err != nil {
+		return nil, err
+	}
+	ep.append(code)
+
+	srv, err := parseGoFile(rootDir, "internal/transport/http/server.go")
+	if err != nil {
+		return nil, err
+	}
+	if srv.hasDecl("decode"+d.Name+"Request") || srv.hasDecl("encode"+d.Name+"Response") {
+		return nil, fmt.Errorf("endpoint %s already exists in %s", d.Name, srv.path)
+	}
+
+	fn = srv.funcDecl("NewServer")
+	if fn == nil {
+		return nil, fmt.Errorf("%s: func NewServer not found", srv.path)
+	}
+	var routerStmt, routeStmt ast.Stmt
+	for _, stmt := range fn.Body.List {
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(stmt.Lhs) == 1 && len(stmt.Rhs) == 1 && selectorCall(stmt.Rhs[0], "mux", "NewRouter") {
+				if id, ok := stmt.Lhs[0].(*ast.Ident); ok {
+					routerStmt, d.Router = stmt, id.Name
+				}
+			}
+		case *ast.ExprStmt:
+			if root, names := callChainRoot(stmt.X); root != nil && root.Name == d.Router && contains(names, "Methods") && !componentRoute(stmt) {
+				routeStmt = stmt
+			}
+		}
+	}
+	if routerStmt == nil {
+		return nil, fmt.Errorf("%s: NewServer doesn't create mux router", srv.path)
+	}
+	if routeStmt == nil {
+		routeStmt = routerStmt
+	}
+
+	handler, err := execSnippet(tpl, "gokit_handler", d)
+	if err != nil {
+		return nil, err
+	}
+	srv.insert(routerStmt.Pos(), handler)
+
+	route, err := execSnippet(tpl, "gokit_route", d)
+	if err != nil {
+		return nil, err
+	}
+	srv.insert(routeStmt.End(), route)
+
+	codec, err := execSnippet(tpl, "gokit_codec", d)
+	if err != nil {
+		return nil, err
+	}
+	var lastCodec *ast.FuncDecl
+	for _, decl := range srv.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+		name := fn.Name.Name
+		if strings.HasPrefix(name, "decode") && strings.HasSuffix(name, "Request") ||
+			strings.HasPrefix(name, "encode") && strings.HasSuffix(name, "Response") {
+			lastCodec = fn
+		}
+	}
+	if lastCodec != nil {
+		srv.insert(lastCodec.End(), codec)
+	} else {
+		srv.append(codec)
+	}
+
+	return []*goFile{ep, srv}, nil
+}
+
+// addGinEndpoint adds the endpoint handler to gin http server, the route is registered in the route group
+// with the longest prefix of the path.
+func addGinEndpoint(rootDir string, tpl *template.Template, d *endpointData) ([]*goFile, error) {
+	srv, err := parseGoFile(rootDir, "internal/transport/http/server.go")
+	if err != nil {
+		return nil, err
+	}
+	if srv.hasDecl(d.Name+"Request") || srv.hasDecl(d.Name+"Response") {
+		return nil, fmt.Errorf("endpoint %s already exists in %s", d.Name, srv.path)
+	}
+
+	fn := srv.funcDecl("NewServer")
+	if fn == nil {
+		return nil, fmt.Errorf("%s: func NewServer not found", srv.path)
+	}
+	d.Logger = "l"
+	if params := fn.Type.Params.List; len(params) != 0 && len(params[0].Names) != 0 {
+		d.Logger = params[0].Names[0].Name
+	}
+
+	// routers are the gin engine and its route groups with their path prefixes.
+	routers := map[string]string{}
+	routerStmts := map[string]ast.Stmt{}
+	routeStmts := map[string]ast.Stmt{}
+	for _, stmt := range fn.Body.List {
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+				continue
+			}
+			id, ok := stmt.Lhs[0].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if selectorCall(stmt.Rhs[0], "gin", "Default") || selectorCall(stmt.Rhs[0], "gin", "New") {
+				routers[id.Name], routerStmts[id.Name] = "", stmt
+				continue
+			}
+			if prefix, ok := groupPrefix(stmt.Rhs[0], routers); ok {
+				routers[id.Name], routerStmts[id.Name] = prefix, stmt
+			}
+		case *ast.ExprStmt:
+			root, names := callChainRoot(stmt.X)
+			if root == nil || len(names) == 0 || !contains(HTTPMethods, names[len(names)-1]) {
+				continue
+			}
+			if _, ok := routers[root.Name]; ok && !componentRoute(stmt) {
+				routeStmts[root.Name] = stmt
+			}
+		}
+	}
+
+	d.Router = ""
+	for name, prefix := range routers {
+		if d.FullPath != prefix && !strings.HasPrefix(d.FullPath, strings.TrimSuffix(prefix, "/")+"/") {
+			continue
+		}
+		if d.Router == "" || len(prefix) > len(routers[d.Router]) || len(prefix) == len(routers[d.Router]) && name < d.Router {
+			d.Router = name
+		}
+	}
+	if d.Router == "" {
+		return nil, fmt.Errorf("%s: NewServer doesn't create gin engine", srv.path)
+	}
+	d.Path = strings.TrimPrefix(d.FullPath, strings.TrimSuffix(routers[d.Router], "/"))
+
+	at, ok := routeStmts[d.Router]
+	if !ok {
+		at = routerStmts[d.Router]
+	}
+	route, err := execSnippet(tpl, "gin_route", d)
+	if err != nil {
+		return nil, err
+	}
+	srv.insert(at.End(), "\n"+route)
+
+	types, err := execSnippet(tpl, "gin_types", d)
+	if err != nil {
+		return nil, err
+	}
+	srv.append(types)
+
+	return []*goFile{srv}, nil
+}
+
+// groupPrefix returns full path prefix of the route group created by expr, e.g. r.Group("/api").
+func groupPrefix(expr ast.Expr, routers map[string]string) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Group" {
+		return "", false
+	}
+	parent, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	parentPrefix, ok := routers[parent.Name]
+	if !ok {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	prefix, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(parentPrefix, "/") + prefix, true
+}
+
+// addEndpointTest adds a test sending request to the endpoint to the application test suite.
+func addEndpointTest(rootDir string, tpl *template.Template, d *endpointData) (*goFile, error) {
+	f, err := parseGoFile(rootDir, "test/app_test.go")
+	if err != nil {
+		return nil, err
+	}
+	if f.typeSpec("APPServerTS") == nil {
+		return nil, fmt.Errorf("%s: test suite APPServerTS not found", f.path)
+	}
+	if f.hasDecl("Test" + d.Name + "Endpoint") {
+		return nil, fmt.Errorf("test of endpoint %s already exists in %s", d.Name, f.path)
+	}
+
+	test, err := execSnippet(tpl, "test", d)
+	if err != nil {
+		return nil, err
+	}
+	f.append(test)
+
+	return f, nil
+}
+
+// componentRoutes are routes generated for optional components, added endpoints are never placed next to them,
+// so removing a component doesn't conflict with added routes.
+var componentRoutes = []string{"/health-check", "/metrics"}
+
+func componentRoute(stmt ast.Stmt) bool {
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			v, err := strconv.Unquote(lit.Value)
+			found = found || err == nil && contains(componentRoutes, v)
+		}
+		return !found
+	})
+	return found
+}
+
+func lastField(fields []*ast.Field) ast.Node {
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields[len(fields)-1]
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAddEndpoint adds endpoints to gokit and gin projects, checks where the routes are registered,
+// that duplicates are rejected and that the project still builds and passes go vet.
+func TestAddEndpoint(t *testing.T) {
+	skipBuilds(t)
+
+	tests := []struct {
+		settings Settings
+		// routes are expected in internal/transport/http/server.go after the endpoints are added.
+		routes []string
+	}{
+		{
+			settings: Settings{Logger: GoKit, Router: GorillaMux},
+			routes:   []string{`Methods("POST").Path("/api/orders")`, `Methods("GET").Path("/status")`},
+		},
+		{
+			settings: Settings{Logger: Zap, Router: GorillaMux, UseJaeger: true},
+			routes:   []string{`Methods("POST").Path("/api/orders")`, `Methods("GET").Path("/status")`},
+		},
+		{
+			settings: Settings{Logger: Zap, Router: GIN},
+			routes:   []string{`api.POST("/orders"`, `r.GET("/status"`},
+		},
+		{
+			settings: Settings{Logger: GoKit, Router: GIN, UseJaeger: true},
+			routes:   []string{`api.POST("/orders"`, `r.GET("/status"`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		s := tt.settings
+		t.Run(fmt.Sprintf("%s-%s-jaeger-%v", s.Router, s.Logger, s.UseJaeger), func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			s.ProjectName, s.ProjectRootDir, s.Database = "svc", dir, NoDb
+			s.UseConsul, s.UsePrometheus = true, true
+			s.Reporter = discardReporter{}
+			if _, err := Run(context.Background(), &s); err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+
+			ctx := context.Background()
+			for _, e := range []Endpoint{
+				{Name: "create-order", Method: "post", Path: "/api/orders"},
+				{Name: "status", Method: "GET", Path: "/status"},
+				// the name starting with non-ASCII letter becomes a valid identifier of local variables.
+				{Name: "ёлка", Method: "GET", Path: "/api/tree"},
+			} {
+				if _, err := AddEndpoint(ctx, dir, e); err != nil {
+					t.Fatalf("add endpoint %s: %v", e.Name, err)
+				}
+			}
+
+			server := readProjectFile(t, dir, "internal/transport/http/server.go")
+			for _, route := range tt.routes {
+				if !strings.Contains(server, route) {
+					t.Errorf("route %s isn't registered:\n%s", route, server)
+				}
+			}
+
+			// the same endpoint is rejected by the declarations already in the project, nothing is changed.
+			before := readProjectFile(t, dir, "test/app_test.go")
+			if _, err := AddEndpoint(ctx, dir, Endpoint{Name: "CreateOrder", Method: "GET", Path: "/api/other"}); err == nil {
+				t.Error("duplicate endpoint is added")
+			}
+			if readProjectFile(t, dir, "internal/transport/http/server.go") != server || readProjectFile(t, dir, "test/app_test.go") != before {
+				t.Error("rejected endpoint changed the project")
+			}
+
+			// added routes aren't placed next to component routes, so removing the component doesn't conflict.
+			report, err := Remove(ctx, dir, PrometheusComponent)
+			if err != nil {
+				t.Fatalf("remove prometheus: %v", err)
+			}
+			if conflicts := report.Conflicts(); len(conflicts) != 0 {
+				t.Fatalf("remove prometheus left conflicts: %v", conflicts)
+			}
+
+			goCommands(t, dir, []string{"go", "mod", "tidy"}, []string{"go", "build", "./..."}, []string{"go", "vet", "./..."})
+		})
+	}
+}
+
+func readProjectFile(t *testing.T, dir, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,116 @@
+package generator
+
+// templateData is the data model shared by all templates and output path patterns.
+type templateData struct {
+	// Module is the go module path used in imports.
+	Module string
+	// Name is the application name used for cmd/<name> directory, binary and service names.
+	Name string
+	// GoVersion is the go version in go.mod, Dockerfile and README.
+	GoVersion string
+
+	UseGoKitLogger bool
+	UseZapLogger   bool
+
+	UseClickhouse bool
+	UsePostgresql bool
+
+	UseGorillaMux bool
+	UseGin        bool
+
+	UseJaeger                 bool
+	UseConsul                 bool
+	UseConsulForConfiguration bool
+	UsePrometheus             bool
+
+	// Requires are modules required by go.mod, see dependencies.
+	Requires []Require
+
+	// Options are answers to questions of the template pack, they are set for pack templates only.
+	Options map[string]interface{}
+}
+
+func newTemplateData(s *Settings) *templateData {
+	d := &templateData{
+		Module:                    s.Module(),
+		Name:                      s.ProjectName,
+		GoVersion:                 s.Go(),
+		UseGoKitLogger:            s.Logger == GoKit,
+		UseZapLogger:              s.Logger == Zap,
+		UseClickhouse:             s.Database == Clickhouse,
+		UsePostgresql:             s.Database == Postgresql,
+		UseGorillaMux:             s.Router == GorillaMux,
+		UseGin:                    s.Router == GIN,
+		UseJaeger:                 s.UseJaeger,
+		UseConsul:                 s.UseConsul,
+		UseConsulForConfiguration: s.SyncConfigWithConsul,
+		UsePrometheus:             s.UsePrometheus,
+	}
+	d.Requires = requires(d)
+	return d
+}
+
+// projectFile describes a generated file.
+type projectFile struct {
+	// template is the name of the template in assets.
+	template string
+	// output is the file path relative to the project root, it's a template executed with templateData.
+	output string
+	// when reports whether the file is generated, nil means always.
+	when func(d *templateData) bool
+	// format makes generator gofmt the rendered file.
+	format bool
+}
+
+// projectFiles lists all generated files in the order of generation, directories are derived from outputs.
+var projectFiles = []projectFile{
+	{template: "gomod", output: "go.mod"},
+	{template: "gitignore", output: ".gitignore"},
+	{template: "dockerfile", output: "Dockerfile"},
+	{template: "makefile", output: "Makefile"},
+	{template: "readme", output: "README.md"},
+	{template: "golangci_cfg", output: ".golangci.yml"},
+	{template: "errcheck_excludes", output: ".errcheck_excludes.txt"},
+	{template: "main", output: "cmd/{{.Name}}/main.go", format: true},
+	{template: "config", output: "internal/config/config.go", format: true},
+	{template: "config_yml", output: "configs/config.yml"},
+	{template: "logger", output: "internal/infrastructure/logger/logger.go", format: true},
+	{
+		template: "tracer",
+		output:   "internal/infrastructure/tracer/jaeger.go",
+		when:     func(d *templateData) bool { return d.UseJaeger },
+		format:   true,
+	},
+	{
+		template: "consul",
+		output:   "internal/infrastructure/consul/consul.go",
+		when:     func(d *templateData) bool { return d.UseConsul },
+		format:   true,
+	},
+	{template: "app", output: "internal/app.go", format: true},
+	{
+		template: "endpoint_gokit",
+		output:   "internal/endpoint/endpoints.go",
+		when:     func(d *templateData) bool { return d.UseGorillaMux },
+		format:   true,
+	},
+	{
+		template: "endpoint_middleware",
+		output:   "internal/endpoint/middleware.go",
+		when:     func(d *templateData) bool { return d.UseGorillaMux },
+		format:   true,
+	},
+	{
+		template: "http_server_gokit",
+		output:   "internal/transport/http/server.go",
+		when:     func(d *templateData) bool { return d.UseGorillaMux },
+		format:   true,
+	},
+	{
+		template: "http_server_gin",
+		output:   "internal/transport/http/server.go",
+		when:     func(d *templateData) bool { return d.UseGin },
+		format:   true,
+	},
+	{template: "app_test", output: "test/app_test.go", format: true},
+}
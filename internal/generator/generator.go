@@ -2,14 +2,14 @@ package generator
 
 import (
 	"bytes"
+	"context"
 	"embed"
 	"fmt"
 	"go/format"
 	"io"
-	"log"
 	"os"
-	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
 	"text/template"
 )
@@ -20,480 +20,370 @@ var templates embed.FS
 
 type generator struct {
 	settings *Settings
+	// files rendered so far.
+	files []File
 }
 
-func Run(settings *Settings) error {
+// File is rendered project file, path is relative to the project root and uses slashes.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Render executes all templates in memory, nothing is written to disk.
+func Render(settings *Settings) ([]File, error) {
 	g := generator{settings: settings}
+	return g.render()
+}
 
-	log.Print("create directories ...")
-	if err := g.createDirectoryLayout(); err != nil {
-		return fmt.Errorf("create directory structure: %w", err)
+// RenderProject renders the project with .skeleton.yml and snapshots of the generated files in memory,
+// it's everything Run writes except vendored dependencies.
+func RenderProject(settings *Settings) ([]File, error) {
+	files, err := Render(settings)
+	if err != nil {
+		return nil, err
 	}
 
-	rootDir := g.settings.ProjectRootDir
-
-	log.Print("create go.mod file ...")
-	if err := execTpl(g.writeGoMod, path.Join(rootDir, "go.mod")); err != nil {
-		return err
+	mf, err := manifestFiles(settings, files)
+	if err != nil {
+		return nil, fmt.Errorf("render project manifest: %w", err)
 	}
+	return append(files, mf...), nil
+}
 
-	log.Print("create .gitignore file ...")
-	if err := execTpl(g.writeGitignore, path.Join(rootDir, ".gitignore")); err != nil {
-		return err
-	}
+// Vendor downloads dependencies of the project in rootDir into its vendor directory,
+// go mod tidy completes go.mod and go.sum with the modules needed by pinned requirements first.
+func Vendor(ctx context.Context, rootDir string) error {
+	return vendor(ctx, rootDir, discardReporter{})
+}
 
-	log.Print("create Dockerfile file ...")
-	if err := execTpl(g.writeDockerfile, path.Join(rootDir, "Dockerfile")); err != nil {
+func vendor(ctx context.Context, rootDir string, r Reporter) error {
+	if err := runCommand(ctx, r, rootDir, "go", "mod", "tidy"); err != nil {
 		return err
 	}
+	return runCommand(ctx, r, rootDir, "go", "mod", "vendor")
+}
 
-	log.Print("create Makefile file ...")
-	if err := execTpl(g.writeMakefile, path.Join(rootDir, "Makefile")); err != nil {
-		return err
-	}
+// Run generates the project in the staging directory and moves it into the project root only on success,
+// on any error everything created by the run is removed. Progress is reported to settings.Reporter,
+// the returned summary isn't reported, so the caller may report it after own steps, e.g. Verify.
+// Cancellation of ctx stops the run and rolls it back, ctx.Err() is returned then.
+func Run(ctx context.Context, settings *Settings) (_ *Summary, err error) {
+	g := generator{settings: settings}
+	r := settings.reporter()
 
-	log.Print("create README.md ...")
-	if err := execTpl(g.writeReadme, path.Join(rootDir, "README.md")); err != nil {
-		return err
+	files, err := g.render()
+	if err != nil {
+		return nil, err
 	}
 
-	log.Print("create golangci-lint yml config file ...")
-	{
-		if err := execTpl(g.writeGOlangCILint, path.Join(rootDir, ".golangci.yml")); err != nil {
-			return err
-		}
+	rootDir := g.settings.ProjectRootDir
 
-		if err := execTpl(g.writeGOlangCILintErrCheckExcludes, path.Join(rootDir, ".errcheck_excludes.txt")); err != nil {
-			return err
-		}
+	paths := []string{ProjectManifestFile}
+	for _, f := range files {
+		paths = append(paths, f.Path)
 	}
-
-	log.Print("create main.go ...")
-	if err := execTplAndFormat(g.writeMain, path.Join(rootDir, "cmd", g.settings.ProjectName, "main.go")); err != nil {
-		return err
+	existing, err := existingFiles(rootDir, paths)
+	if err != nil {
+		return nil, err
 	}
-
-	log.Print("create config package ...")
-	if err := execTplAndFormat(g.writeConfig, path.Join(rootDir, "internal/config/config.go")); err != nil {
-		return err
+	if len(existing) != 0 && !settings.Force && !settings.SkipExisting {
+		return nil, &ConflictError{Files: existing}
 	}
-
-	log.Print("create config.yml example ...")
-	if err := execTpl(g.writeConfigYml, path.Join(rootDir, "configs/config.yml")); err != nil {
-		return err
+	isExisting := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		isExisting[p] = true
 	}
 
-	log.Print("create infrastructure/logger package ...")
-	if err := execTplAndFormat(g.writeLogger, path.Join(rootDir, "internal/infrastructure/logger/logger.go")); err != nil {
-		return err
+	st, err := newStaging(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
 	}
-
-	if settings.UseJaeger {
-		log.Print("create infrastructure/tracer package ...")
-		if err := execTplAndFormat(g.writeTracer, path.Join(rootDir, "internal/infrastructure/tracer/jaeger.go")); err != nil {
-			return err
+	defer func() {
+		if err != nil {
+			r.Step("rollback")
+			st.rollback()
+			return
 		}
-	}
-
-	if settings.UseConsul {
-		log.Print("create infrastructure/consul package ...")
-		if err := execTplAndFormat(g.writeConsul, path.Join(rootDir, "internal/infrastructure/consul/consul.go")); err != nil {
-			return err
+		st.cleanup()
+	}()
+
+	r.Step("create directories")
+	if err = g.createDirectoryLayout(st.dir, r); err != nil {
+		return nil, fmt.Errorf("create directory structure: %w", err)
+	}
+
+	var written []File
+	for _, f := range files {
+		if settings.SkipExisting && isExisting[f.Path] {
+			r.File(f.Path, len(f.Content), true)
+			// existing file is staged to keep the staged project complete for go mod vendor.
+			if err = st.copyFromRoot(f.Path); err != nil {
+				return nil, err
+			}
+			continue
 		}
-	}
-
-	log.Print("create app.go ...")
-	if err := execTplAndFormat(g.writeApp, path.Join(rootDir, "internal/app.go")); err != nil {
-		return err
-	}
 
-	if g.settings.Router == GorillaMux {
-		log.Print("create endpoint package ...")
-		if err := execTplAndFormat(g.writeEndpoints, path.Join(rootDir, "internal/endpoint/endpoints.go")); err != nil {
-			return err
-		}
-		if err := execTplAndFormat(g.writeEndpointsMiddlewares, path.Join(rootDir, "internal/endpoint/middleware.go")); err != nil {
-			return err
+		r.File(f.Path, len(f.Content), false)
+		if err = os.WriteFile(filepath.Join(st.dir, filepath.FromSlash(f.Path)), f.Content, 0644); err != nil {
+			return nil, err
 		}
+		written = append(written, f)
 	}
 
-	log.Print("create transport/http package ...")
-	switch settings.Router {
-	case GorillaMux:
-		if err := execTplAndFormat(g.writeGoKitHttpServer, path.Join(rootDir, "internal/transport/http/server.go")); err != nil {
-			return err
+	summary := newSummary(settings, written)
+	if settings.SkipExisting && isExisting[ProjectManifestFile] {
+		r.File(ProjectManifestFile, 0, true)
+	} else {
+		if err = writeProjectManifest(st.dir, settings, written, r); err != nil {
+			return nil, fmt.Errorf("write project manifest: %w", err)
 		}
-	case GIN:
-		if err := execTplAndFormat(g.writeGinHttpServer, path.Join(rootDir, "internal/transport/http/server.go")); err != nil {
-			return err
-		}
-	}
-
-	log.Print("create test package ...")
-	if err := execTplAndFormat(g.writeTest, path.Join(rootDir, "test/app_test.go")); err != nil {
-		return err
+		summary.Files = append(summary.Files, ProjectManifestFile)
 	}
 
 	if settings.WithDeps {
-		log.Print("download dependencies ...")
-		cmd := exec.Command("go", "mod", "vendor")
-		cmd.Dir = rootDir
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("go mod vendor command: %w", err)
+		r.Step("download dependencies")
+		if err = vendor(ctx, st.dir, r); err != nil {
+			return nil, err
 		}
 	}
 
-	log.Print("DONE!")
-
-	return nil
-}
-
-func execTpl(executor func(w io.Writer) error, filePath string) error {
-	buff := &bytes.Buffer{}
-	if err := executor(buff); err != nil {
-		return fmt.Errorf("on exec template: %w, file: %s", err, filePath)
+	r.Step("move project into place")
+	if err = st.commit(ctx); err != nil {
+		return nil, fmt.Errorf("move project into place: %w", err)
 	}
 
-	return os.WriteFile(filePath, buff.Bytes(), 0644)
+	return summary, nil
 }
 
-func execTplAndFormat(executor func(w io.Writer) error, filePath string) error {
-	buff := &bytes.Buffer{}
-	if err := executor(buff); err != nil {
-		return fmt.Errorf("on exec template: %w, file: %s", err, filePath)
-	}
-
-	source, err := format.Source(buff.Bytes())
-	if err != nil {
-		return fmt.Errorf("on format sources: %w, file: %s", err, filePath)
-	}
-
-	return os.WriteFile(filePath, source, 0644)
+// ConflictError is returned by Run when generated files already exist in the project directory.
+type ConflictError struct {
+	Files []string
 }
 
-func (g *generator) createDirectoryLayout() error {
-	err := os.Mkdir(path.Join(g.settings.ProjectRootDir, "cmd/"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d files already exist in the project directory: %s", len(e.Files), strings.Join(e.Files, ", "))
+}
 
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "cmd/", g.settings.ProjectName), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
+// existingFiles returns paths which exist in the rootDir.
+func existingFiles(rootDir string, paths []string) ([]string, error) {
+	var existing []string
+	for _, p := range paths {
+		_, err := os.Stat(filepath.Join(rootDir, filepath.FromSlash(p)))
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, err
+		default:
+			existing = append(existing, p)
+		}
 	}
+	return existing, nil
+}
 
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "test/"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
+// render executes all templates in memory.
+func (g *generator) render() ([]File, error) {
+	g.files = nil
 
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
+	data := newTemplateData(g.settings)
+	for _, pf := range projectFiles {
+		if pf.when != nil && !pf.when(data) {
+			continue
+		}
 
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/infrastructure"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
+		tpl, err := g.createTemplate(pf.template)
+		if err != nil {
+			return nil, err
+		}
+		if err = g.renderFile(tpl, pf.output, pf.format, data); err != nil {
+			return nil, err
+		}
 	}
 
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/infrastructure/logger"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
+	if err := g.renderPacks(data); err != nil {
+		return nil, err
 	}
 
-	if g.settings.UseJaeger {
-		err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/infrastructure/tracer"), 0755)
-		if err != nil && !os.IsExist(err) {
-			return err
-		}
-	}
+	return g.files, nil
+}
 
-	if g.settings.UseConsul {
-		err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/infrastructure/consul"), 0755)
-		if err != nil && !os.IsExist(err) {
+// writeFiles writes files into rootDir creating missing directories.
+func writeFiles(rootDir string, files []File) error {
+	for _, f := range files {
+		p := filepath.Join(rootDir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
 			return err
 		}
-	}
-
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/config"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
-
-	if g.settings.Router == GorillaMux {
-		err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/endpoint"), 0755)
-		if err != nil && !os.IsExist(err) {
+		if err := os.WriteFile(p, f.Content, 0644); err != nil {
 			return err
 		}
 	}
-
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/transport"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
-
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "internal/transport/http"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
-
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "configs"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
-
-	err = os.Mkdir(path.Join(g.settings.ProjectRootDir, "vendor"), 0755)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
-
 	return nil
 }
 
-func (g *generator) createTemplate(fileName string) (*template.Template, error) {
-	return template.New(fileName).Funcs(template.FuncMap{
-		"log":    makeLogFunc(g.settings.Logger),
-		"logErr": makeLogErrFunc(g.settings.Logger),
-	}).ParseFS(templates, "assets/"+fileName)
-}
-
-func (g *generator) writeGoMod(w io.Writer) error {
-	tpl, err := g.createTemplate("gomod")
+// renderFile executes tpl into the file with path rendered from output pattern.
+func (g *generator) renderFile(tpl *template.Template, output string, format bool, data *templateData) error {
+	filePath, err := g.renderOutputPath(output, data)
 	if err != nil {
 		return err
 	}
 
-	return tpl.Execute(w, map[string]interface{}{"module": g.settings.ProjectName})
-}
-
-func (g *generator) writeGitignore(w io.Writer) error {
-	tpl, err := g.createTemplate("gitignore")
-	if err != nil {
-		return err
+	for _, f := range g.files {
+		if f.Path == filePath {
+			return fmt.Errorf("file %s is already generated", filePath)
+		}
 	}
 
-	return tpl.Execute(w, map[string]interface{}{})
-}
-
-func (g *generator) writeDockerfile(w io.Writer) error {
-	tpl, err := g.createTemplate("dockerfile")
-	if err != nil {
-		return err
+	executor := func(w io.Writer) error {
+		return tpl.Execute(w, data)
 	}
-
-	return tpl.Execute(w, map[string]interface{}{"module": g.settings.ProjectName})
-}
-
-func (g *generator) writeMakefile(w io.Writer) error {
-	tpl, err := g.createTemplate("makefile")
-	if err != nil {
-		return err
+	if format {
+		return g.execTplAndFormat(executor, filePath)
 	}
-
-	return tpl.Execute(w, map[string]interface{}{"module": g.settings.ProjectName})
+	return g.execTpl(executor, filePath)
 }
 
-func (g *generator) writeGOlangCILint(w io.Writer) error {
-	tpl, err := g.createTemplate("golangci_cfg")
+// renderOutputPath executes output path pattern, result must be inside the project.
+func (g *generator) renderOutputPath(output string, data *templateData) (string, error) {
+	tpl, err := g.newTemplate("output").Parse(output)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("output path %s: %w", output, err)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{})
-}
-
-func (g *generator) writeGOlangCILintErrCheckExcludes(w io.Writer) error {
-	tpl, err := g.createTemplate("errcheck_excludes")
-	if err != nil {
-		return err
+	buff := &bytes.Buffer{}
+	if err = tpl.Execute(buff, data); err != nil {
+		return "", fmt.Errorf("output path %s: %w", output, err)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{})
-}
-
-func (g *generator) writeReadme(w io.Writer) error {
-	tpl, err := g.createTemplate("readme")
-	if err != nil {
-		return err
+	filePath := path.Clean(strings.TrimSpace(buff.String()))
+	if path.IsAbs(filePath) || filePath == ".." || strings.HasPrefix(filePath, "../") {
+		return "", fmt.Errorf("output path %s is outside of the project", filePath)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{
-		"module":          strings.ToUpper(g.settings.ProjectName),
-		"use_clickhouse":  g.settings.Database == Clickhouse,
-		"use_postgresql":  g.settings.Database == Postgresql,
-		"use_gorilla_mux": g.settings.Router == GorillaMux,
-		"use_gin":         g.settings.Router == GIN,
-		"use_jaeger":      g.settings.UseJaeger,
-		"use_consul":      g.settings.UseConsul,
-		"use_prometheus":  g.settings.UsePrometheus,
-	})
+	return filePath, nil
 }
 
-func (g *generator) writeMain(w io.Writer) error {
-	tpl, err := g.createTemplate("main")
-	if err != nil {
-		return err
+func (g *generator) execTpl(executor func(w io.Writer) error, filePath string) error {
+	buff := &bytes.Buffer{}
+	if err := executor(buff); err != nil {
+		return fmt.Errorf("on exec template: %w, file: %s", err, filePath)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{
-		"module":           g.settings.ProjectName,
-		"use_gokit_logger": g.settings.Logger == GoKit,
-		"use_zap_logger":   g.settings.Logger == Zap,
-		"use_jaeger":       g.settings.UseJaeger,
-		"use_consul":       g.settings.UseConsul,
-	})
+	g.files = append(g.files, File{Path: filePath, Content: buff.Bytes()})
+	return nil
 }
 
-func (g *generator) writeLogger(w io.Writer) error {
-	tpl, err := g.createTemplate("logger")
-	if err != nil {
-		return err
+func (g *generator) execTplAndFormat(executor func(w io.Writer) error, filePath string) error {
+	buff := &bytes.Buffer{}
+	if err := executor(buff); err != nil {
+		return fmt.Errorf("on exec template: %w, file: %s", err, filePath)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{
-		"use_gokit_logger": g.settings.Logger == GoKit,
-		"use_zap_logger":   g.settings.Logger == Zap,
-	})
-}
-
-func (g *generator) writeConfig(w io.Writer) error {
-	tpl, err := g.createTemplate("config")
+	source, err := format.Source(buff.Bytes())
 	if err != nil {
-		return err
+		return fmt.Errorf("on format sources: %w, file: %s", err, filePath)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{
-		"module":                       g.settings.ProjectName,
-		"use_clickhouse":               g.settings.Database == Clickhouse,
-		"use_postgresql":               g.settings.Database == Postgresql,
-		"use_jaeger":                   g.settings.UseJaeger,
-		"use_consul":                   g.settings.UseConsul,
-		"use_consul_for_configuration": g.settings.SyncConfigWithConsul,
-	})
+	g.files = append(g.files, File{Path: filePath, Content: source})
+	return nil
 }
 
-func (g *generator) writeConfigYml(w io.Writer) error {
-	tpl, err := g.createTemplate("config_yml")
-	if err != nil {
-		return err
+func (g *generator) createDirectoryLayout(rootDir string, r Reporter) error {
+	for _, dir := range g.directories() {
+		err := os.Mkdir(filepath.Join(rootDir, filepath.FromSlash(dir)), 0755)
+		if err != nil && !os.IsExist(err) {
+			return err
+		}
+		r.Dir(dir)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{
-		"module":         g.settings.ProjectName,
-		"use_clickhouse": g.settings.Database == Clickhouse,
-		"use_postgresql": g.settings.Database == Postgresql,
-		"use_jaeger":     g.settings.UseJaeger,
-		"use_consul":     g.settings.UseConsul,
-	})
+	return nil
 }
 
-func (g *generator) writeTracer(w io.Writer) error {
-	tpl, err := g.createTemplate("tracer")
-	if err != nil {
-		return err
-	}
-
-	return tpl.Execute(w, map[string]interface{}{})
-}
+// directories returns directories of the rendered files relative to the project root, parents go first.
+func (g *generator) directories() []string {
+	var dirs []string
+	seen := map[string]bool{}
 
-func (g *generator) writeConsul(w io.Writer) error {
-	tpl, err := g.createTemplate("consul")
-	if err != nil {
-		return err
+	var add func(dir string)
+	add = func(dir string) {
+		if dir == "." || seen[dir] {
+			return
+		}
+		add(path.Dir(dir))
+		seen[dir] = true
+		dirs = append(dirs, dir)
 	}
 
-	return tpl.Execute(w, map[string]interface{}{})
-}
-
-func (g *generator) writeApp(w io.Writer) error {
-	tpl, err := g.createTemplate("app")
-	if err != nil {
-		return err
+	for _, f := range g.files {
+		add(path.Dir(f.Path))
 	}
 
-	return tpl.Execute(w, map[string]interface{}{
-		"module":           g.settings.ProjectName,
-		"use_clickhouse":   g.settings.Database == Clickhouse,
-		"use_postgresql":   g.settings.Database == Postgresql,
-		"use_gokit_logger": g.settings.Logger == GoKit,
-		"use_zap_logger":   g.settings.Logger == Zap,
-		"use_gorilla_mux":  g.settings.Router == GorillaMux,
-		"use_gin":          g.settings.Router == GIN,
-	})
+	return dirs
 }
 
-func (g *generator) writeEndpoints(w io.Writer) error {
-	tpl, err := g.createTemplate("endpoint_gokit")
-	if err != nil {
-		return err
+// createTemplate parses template from the templates overlay directory if it has the file with the same name,
+// otherwise embedded asset is used.
+func (g *generator) createTemplate(fileName string) (*template.Template, error) {
+	tpl := g.newTemplate(fileName)
+
+	if g.settings.TemplatesDir != "" {
+		override := filepath.Join(g.settings.TemplatesDir, fileName)
+		_, err := os.Stat(override)
+		switch {
+		case err == nil:
+			return tpl.ParseFiles(override)
+		case !os.IsNotExist(err):
+			return nil, err
+		}
 	}
 
-	return tpl.Execute(w, map[string]interface{}{
-		"use_jaeger": g.settings.UseJaeger,
-	})
+	return tpl.ParseFS(templates, "assets/"+fileName)
 }
 
-func (g *generator) writeEndpointsMiddlewares(w io.Writer) error {
-	tpl, err := g.createTemplate("endpoint_middleware")
-	if err != nil {
-		return err
-	}
-
-	return tpl.Execute(w, map[string]interface{}{
-		"module":           g.settings.ProjectName,
-		"use_jaeger":       g.settings.UseJaeger,
-		"use_consul":       g.settings.UseConsul,
-		"use_gokit_logger": g.settings.Logger == GoKit,
-		"use_zap_logger":   g.settings.Logger == Zap,
+// newTemplate returns empty template with generator functions.
+func (g *generator) newTemplate(name string) *template.Template {
+	return template.New(name).Funcs(template.FuncMap{
+		"log":    makeLogFunc(g.settings.Logger),
+		"logErr": makeLogErrFunc(g.settings.Logger),
+		"upper":  strings.ToUpper,
+		"any":    makeAnyFunc(g.settings.Go()),
+		// stdlog and stdlogErr are used by main only, to report errors before the application logger exists.
+		"stdlog":    makeStdlogFunc(g.settings.Go()),
+		"stdlogErr": makeStdlogErrFunc(g.settings.Go()),
 	})
 }
 
-func (g *generator) writeGoKitHttpServer(w io.Writer) error {
-	tpl, err := g.createTemplate("http_server_gokit")
-	if err != nil {
-		return err
+// makeAnyFunc returns function writing the empty interface type, any is used since go 1.18.
+func makeAnyFunc(goVersion string) func() string {
+	return func() string {
+		if goVersionAtLeast(goVersion, 18) {
+			return "any"
+		}
+		return "interface{}"
 	}
-
-	return tpl.Execute(w, map[string]interface{}{
-		"module":           g.settings.ProjectName,
-		"use_clickhouse":   g.settings.Database == Clickhouse,
-		"use_postgresql":   g.settings.Database == Postgresql,
-		"use_jaeger":       g.settings.UseJaeger,
-		"use_consul":       g.settings.UseConsul,
-		"use_gokit_logger": g.settings.Logger == GoKit,
-		"use_zap_logger":   g.settings.Logger == Zap,
-		"use_prometheus":   g.settings.UsePrometheus,
-	})
 }
 
-func (g *generator) writeGinHttpServer(w io.Writer) error {
-	tpl, err := g.createTemplate("http_server_gin")
-	if err != nil {
-		return err
+// makeStdlogFunc returns function writing the import path of the standard logger, log/slog is used since go 1.21.
+func makeStdlogFunc(goVersion string) func() string {
+	return func() string {
+		if goVersionAtLeast(goVersion, 21) {
+			return "log/slog"
+		}
+		return "log"
 	}
-
-	return tpl.Execute(w, map[string]interface{}{
-		"module":           g.settings.ProjectName,
-		"use_clickhouse":   g.settings.Database == Clickhouse,
-		"use_postgresql":   g.settings.Database == Postgresql,
-		"use_jaeger":       g.settings.UseJaeger,
-		"use_consul":       g.settings.UseConsul,
-		"use_gokit_logger": g.settings.Logger == GoKit,
-		"use_zap_logger":   g.settings.Logger == Zap,
-		"use_prometheus":   g.settings.UsePrometheus,
-	})
 }
 
-func (g *generator) writeTest(w io.Writer) error {
-	tpl, err := g.createTemplate("app_test")
-	if err != nil {
-		return err
+// makeStdlogErrFunc returns function writing the error logged by the standard logger.
+func makeStdlogErrFunc(goVersion string) func(msg, err string) string {
+	if goVersionAtLeast(goVersion, 21) {
+		return func(msg, err string) string {
+			return "slog.Error(\"" + msg + "\", \"err\", " + err + ")"
+		}
+	}
+	return func(msg, err string) string {
+		return "log.Println(\"" + msg + "\", " + err + ")"
 	}
-
-	return tpl.Execute(w, map[string]interface{}{
-		"module": g.settings.ProjectName,
-	})
 }
 
 func makeLogFunc(logger LoggerChoice) func(logger, lvl string, msg string) string {
@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// goFile is a go source file of the generated project edited by insertions at positions found in its syntax tree,
+// the result is gofmt-ed so inserted code doesn't need exact indentation.
+type goFile struct {
+	path  string
+	src   []byte
+	fset  *token.FileSet
+	file  *ast.File
+	edits []insertion
+}
+
+// insertion replaces source from offset to end with text, end equals offset for pure insertions.
+type insertion struct {
+	offset int
+	end    int
+	text   string
+}
+
+func parseGoFile(rootDir, path string) (*goFile, error) {
+	src, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, err
+	}
+	return parseGoSource(path, src)
+}
+
+func parseGoSource(path string, src []byte) (*goFile, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goFile{path: path, src: src, fset: fset, file: file}, nil
+}
+
+// insert adds text at pos, texts inserted at the same position keep the order of calls.
+func (f *goFile) insert(pos token.Pos, text string) {
+	offset := f.fset.Position(pos).Offset
+	f.edits = append(f.edits, insertion{offset: offset, end: offset, text: text})
+}
+
+// replace replaces source of the node with text.
+func (f *goFile) replace(n ast.Node, text string) {
+	f.edits = append(f.edits, insertion{
+		offset: f.fset.Position(n.Pos()).Offset,
+		end:    f.fset.Position(n.End()).Offset,
+		text:   text,
+	})
+}
+
+// append adds text to the end of file.
+func (f *goFile) append(text string) {
+	f.edits = append(f.edits, insertion{offset: len(f.src), end: len(f.src), text: text})
+}
+
+// apply returns formatted source with all insertions.
+func (f *goFile) apply() ([]byte, error) {
+	edits := make([]insertion, len(f.edits))
+	copy(edits, f.edits)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset < edits[j].offset })
+
+	var res []byte
+	last := 0
+	for _, e := range edits {
+		res = append(res, f.src[last:e.offset]...)
+		res = append(res, e.text...)
+		last = e.end
+	}
+	res = append(res, f.src[last:]...)
+
+	formatted, err := format.Source(res)
+	if err != nil {
+		return nil, fmt.Errorf("format %s: %w", f.path, err)
+	}
+	return formatted, nil
+}
+
+// funcDecl returns top-level function (not method) with the name.
+func (f *goFile) funcDecl(name string) *ast.FuncDecl {
+	for _, d := range f.file.Decls {
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name && fn.Body != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// typeSpec returns top-level type declaration with the name.
+func (f *goFile) typeSpec(name string) *ast.TypeSpec {
+	for _, d := range f.file.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gd.Specs {
+			if ts := s.(*ast.TypeSpec); ts.Name.Name == name {
+				return ts
+			}
+		}
+	}
+	return nil
+}
+
+// hasDecl reports whether the file declares top-level type, function or method with the name.
+func (f *goFile) hasDecl(name string) bool {
+	if f.typeSpec(name) != nil {
+		return true
+	}
+	for _, d := range f.file.Decls {
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Name.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorCall reports whether expr is a call of pkg.name, e.g. mux.NewRouter().
+func selectorCall(expr ast.Expr, pkg, name string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && (pkg == "" || x.Name == pkg)
+}
+
+// callChainRoot returns identifier the chain of method calls starts from, e.g. r for r.Methods("GET").Path("/").
+func callChainRoot(expr ast.Expr) (*ast.Ident, []string) {
+	var names []string
+	for {
+		switch e := expr.(type) {
+		case *ast.CallExpr:
+			expr = e.Fun
+		case *ast.SelectorExpr:
+			names = append(names, e.Sel.Name)
+			expr = e.X
+		case *ast.Ident:
+			return e, names
+		default:
+			return nil, names
+		}
+	}
+}
+
+// insertItem inserts text as the new last item of a list closed at closing position, e.g. struct fields,
+// var specs or elements of composite literal, sep is written between items.
+func (f *goFile) insertItem(closing token.Pos, last ast.Node, sep, text string) {
+	if last == nil {
+		f.insert(closing, text+sep+"\n")
+		return
+	}
+	f.insert(last.End(), sep+"\n"+text)
+}
+
+// unwrapCalls replaces calls of wrappers like F(args)(x) with x, e.g. TraceLoggerMiddleware()(MakePingEndpoint()),
+// names are function names optionally with package, e.g. kitopentracing.TraceServer.
+// Source without such calls is returned as is.
+func unwrapCalls(path string, src []byte, names []string) ([]byte, error) {
+	for {
+		f, err := parseGoSource(path, src)
+		if err != nil {
+			return nil, err
+		}
+
+		ast.Inspect(f.file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return true
+			}
+			wrapper, ok := call.Fun.(*ast.CallExpr)
+			if !ok || !contains(names, exprName(wrapper.Fun)) {
+				return true
+			}
+			arg := call.Args[0]
+			f.replace(call, string(f.src[f.fset.Position(arg.Pos()).Offset:f.fset.Position(arg.End()).Offset]))
+			// nested wrappers are unwrapped by the next pass.
+			return false
+		})
+		if len(f.edits) == 0 {
+			return src, nil
+		}
+
+		if src, err = f.apply(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// exprName returns name of identifier or selector like pkg.Name, other expressions have no name.
+func exprName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			return x.Name + "." + e.Sel.Name
+		}
+	}
+	return ""
+}
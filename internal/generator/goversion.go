@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DetectGoVersion returns major.minor version of the go toolchain found in PATH,
+// version of the toolchain the generator was built with is used if go isn't found, MinGoVersion if it isn't known.
+func DetectGoVersion() string {
+	v := runtime.Version()
+	if out, err := exec.Command("go", "env", "GOVERSION").Output(); err == nil {
+		v = strings.TrimSpace(string(out))
+	}
+
+	if minor, ok := parseGoVersion(strings.TrimPrefix(v, "go")); ok {
+		return fmt.Sprintf("1.%d", minor)
+	}
+	return MinGoVersion
+}
+
+// parseGoVersion returns minor version of go 1.x version like 1.21 or 1.21.3.
+func parseGoVersion(v string) (int, bool) {
+	parts := strings.Split(v, ".")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != "1" {
+		return 0, false
+	}
+	// pre-release suffix like 1.22rc1 belongs to the last part.
+	last := len(parts) - 1
+	if i := strings.IndexFunc(parts[last], func(r rune) bool { return r < '0' || r > '9' }); i > 0 {
+		parts[last] = parts[last][:i]
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return 0, false
+	}
+	if len(parts) == 3 {
+		if _, err = strconv.Atoi(parts[2]); err != nil {
+			return 0, false
+		}
+	}
+	return minor, true
+}
+
+// checkGoVersion checks that v is a go version supported by templates.
+func checkGoVersion(v string) error {
+	minor, ok := parseGoVersion(v)
+	if !ok {
+		return &FieldError{Field: "go_version", Value: v, Reason: "expected version like 1.21 or 1.21.3"}
+	}
+	if min, _ := parseGoVersion(MinGoVersion); minor < min {
+		return &FieldError{Field: "go_version", Value: v, Reason: "must be at least " + MinGoVersion + ", pinned modules need it"}
+	}
+	return nil
+}
+
+// checkStoredGoVersion checks the go version stored in .skeleton.yml, versions older than MinGoVersion are accepted.
+func checkStoredGoVersion(v string) error {
+	if _, ok := parseGoVersion(v); !ok {
+		return &FieldError{Field: "go_version", Value: v, Reason: "expected version like 1.21 or 1.21.3"}
+	}
+	return nil
+}
+
+// raiseGoVersion sets the go version of s to MinGoVersion if it's older and returns the replaced version,
+// it returns empty string if the version isn't changed.
+func raiseGoVersion(s *Settings) (old string) {
+	min, _ := parseGoVersion(MinGoVersion)
+	if minor, ok := parseGoVersion(s.Go()); !ok || minor >= min {
+		return ""
+	}
+	old, s.GoVersion = s.Go(), MinGoVersion
+	return old
+}
+
+// goVersionAtLeast reports whether version v has features of go 1.minor.
+func goVersionAtLeast(v string, minor int) bool {
+	m, ok := parseGoVersion(v)
+	return ok && m >= minor
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckGoVersion(t *testing.T) {
+	want := 0
+	for _, dep := range dependencies {
+		if dep.goVersion == "" {
+			continue
+		}
+		minor, ok := parseGoVersion(dep.goVersion)
+		if !ok {
+			t.Fatalf("go version %q of %s is invalid", dep.goVersion, dep.Path)
+		}
+		if minor > want {
+			want = minor
+		}
+	}
+	if MinGoVersion != fmt.Sprintf("1.%d", want) {
+		t.Fatalf("MinGoVersion = %s, want 1.%d", MinGoVersion, want)
+	}
+
+	tests := []struct {
+		version string
+		valid   bool
+	}{
+		{version: fmt.Sprintf("1.%d", want-1), valid: false},
+		{version: fmt.Sprintf("1.%d", want), valid: true},
+		{version: fmt.Sprintf("1.%d.3", want), valid: true},
+		{version: fmt.Sprintf("1.%drc1", want+2), valid: true},
+		{version: "2.0", valid: false},
+		{version: "1", valid: false},
+		{version: "", valid: false},
+	}
+	for _, tt := range tests {
+		if err := checkGoVersion(tt.version); (err == nil) != tt.valid {
+			t.Errorf("checkGoVersion(%q) = %v, want valid %v", tt.version, err, tt.valid)
+		}
+	}
+}
+
+// TestRaiseStoredGoVersion checks that add accepts the project stored with go version older than MinGoVersion
+// and moves it to MinGoVersion.
+func TestRaiseStoredGoVersion(t *testing.T) {
+	dir := t.TempDir()
+	s := &Settings{ProjectName: "svc", ProjectRootDir: dir, GoVersion: "1.17", Logger: Zap, Database: NoDb, Router: GIN,
+		Reporter: discardReporter{}}
+	if _, err := Run(context.Background(), s); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	report, err := Add(context.Background(), dir, PrometheusComponent)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if report.RaisedGoVersion != "1.17" {
+		t.Errorf("RaisedGoVersion = %q, want 1.17", report.RaisedGoVersion)
+	}
+	if conflicts := report.Conflicts(); len(conflicts) != 0 {
+		t.Fatalf("add left conflicts: %v", conflicts)
+	}
+
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(goMod), "\ngo "+MinGoVersion+"\n") {
+		t.Errorf("go.mod doesn't require go %s:\n%s", MinGoVersion, goMod)
+	}
+	pm, err := ReadProjectManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pm.Settings.GoVersion != MinGoVersion {
+		t.Errorf("stored go version = %s, want %s", pm.Settings.GoVersion, MinGoVersion)
+	}
+}
+
+// TestStdlogGoVersion checks that main reports the configuration error with log/slog since go 1.21 only.
+func TestStdlogGoVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "1.20", want: `log.Println("load configuration", err)`},
+		{version: "1.21", want: `slog.Error("load configuration", "err", err)`},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		s := &Settings{ProjectName: "svc", ProjectRootDir: dir, GoVersion: tt.version, Logger: Zap, Database: NoDb, Router: GIN,
+			Reporter: discardReporter{}}
+		if _, err := Run(context.Background(), s); err != nil {
+			t.Fatalf("generate with go %s: %v", tt.version, err)
+		}
+		if main := readProjectFile(t, dir, "cmd/svc/main.go"); !strings.Contains(main, tt.want) {
+			t.Errorf("main.go for go %s doesn't have %s:\n%s", tt.version, tt.want, main)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+// Manifest is serialisable form of Settings used in settings files (skeleton.yml).
+// Empty fields are treated as not set, so manifest may describe only part of the settings.
+type Manifest struct {
+	Name                 string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Module               string            `yaml:"module,omitempty" json:"module,omitempty"`
+	GoVersion            string            `yaml:"go_version,omitempty" json:"go_version,omitempty"`
+	Logger               LoggerChoice      `yaml:"logger,omitempty" json:"logger,omitempty"`
+	Database             DBChoice          `yaml:"db,omitempty" json:"db,omitempty"`
+	Router               RouterChoice      `yaml:"router,omitempty" json:"router,omitempty"`
+	UseConsul            *bool             `yaml:"consul,omitempty" json:"consul,omitempty"`
+	SyncConfigWithConsul *bool             `yaml:"consul_sync_config,omitempty" json:"consul_sync_config,omitempty"`
+	UseJaeger            *bool             `yaml:"jaeger,omitempty" json:"jaeger,omitempty"`
+	UsePrometheus        *bool             `yaml:"prometheus,omitempty" json:"prometheus,omitempty"`
+	TemplatesDir         string            `yaml:"templates_dir,omitempty" json:"templates_dir,omitempty"`
+	Packs                []string          `yaml:"packs,omitempty" json:"packs,omitempty"`
+	PackOptions          map[string]string `yaml:"pack_options,omitempty" json:"pack_options,omitempty"`
+}
+
+// NewManifest returns manifest with all fields taken from s.
+func NewManifest(s *Settings) *Manifest {
+	return &Manifest{
+		Name:                 s.ProjectName,
+		Module:               s.ModulePath,
+		GoVersion:            s.GoVersion,
+		Logger:               s.Logger,
+		Database:             s.Database,
+		Router:               s.Router,
+		UseConsul:            boolPtr(s.UseConsul),
+		SyncConfigWithConsul: boolPtr(s.SyncConfigWithConsul),
+		UseJaeger:            boolPtr(s.UseJaeger),
+		UsePrometheus:        boolPtr(s.UsePrometheus),
+		TemplatesDir:         s.TemplatesDir,
+		Packs:                s.Packs,
+		PackOptions:          s.PackOptions,
+	}
+}
+
+// LoadManifest reads manifest in YAML or JSON format.
+func LoadManifest(r io.Reader) (*Manifest, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	var m Manifest
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Validate checks values of the fields which are set.
+func (m *Manifest) Validate() error {
+	return m.validate(checkGoVersion)
+}
+
+// validate checks values of the fields which are set, the go version is checked by checkGo.
+func (m *Manifest) validate(checkGo func(v string) error) error {
+	if m.Name != "" {
+		if err := checkName(m.Name); err != nil {
+			return err
+		}
+	}
+	if m.Module != "" {
+		if err := checkModulePath(m.Module); err != nil {
+			return err
+		}
+	}
+	if m.GoVersion != "" {
+		if err := checkGo(m.GoVersion); err != nil {
+			return err
+		}
+	}
+	if m.Logger != "" && !m.Logger.valid() {
+		return &FieldError{Field: "logger", Value: m.Logger, Reason: "expected one of: " + join(Loggers)}
+	}
+	if m.Database != "" && !m.Database.valid() {
+		return &FieldError{Field: "db", Value: m.Database, Reason: "expected one of: " + join(Databases)}
+	}
+	if m.Router != "" && !m.Router.valid() {
+		return &FieldError{Field: "router", Value: m.Router, Reason: "expected one of: " + join(Routers)}
+	}
+	if m.SyncConfigWithConsul != nil && *m.SyncConfigWithConsul && m.UseConsul != nil && !*m.UseConsul {
+		return &FieldError{Field: "consul_sync_config", Value: true, Reason: "requires consul"}
+	}
+
+	return nil
+}
+
+// Override replaces fields of m by fields which are set in o.
+func (m *Manifest) Override(o *Manifest) {
+	if o.Name != "" {
+		m.Name = o.Name
+	}
+	if o.Module != "" {
+		m.Module = o.Module
+	}
+	if o.GoVersion != "" {
+		m.GoVersion = o.GoVersion
+	}
+	if o.Logger != "" {
+		m.Logger = o.Logger
+	}
+	if o.Database != "" {
+		m.Database = o.Database
+	}
+	if o.Router != "" {
+		m.Router = o.Router
+	}
+	if o.UseConsul != nil {
+		m.UseConsul = o.UseConsul
+	}
+	if o.SyncConfigWithConsul != nil {
+		m.SyncConfigWithConsul = o.SyncConfigWithConsul
+	}
+	if o.UseJaeger != nil {
+		m.UseJaeger = o.UseJaeger
+	}
+	if o.UsePrometheus != nil {
+		m.UsePrometheus = o.UsePrometheus
+	}
+	if o.TemplatesDir != "" {
+		m.TemplatesDir = o.TemplatesDir
+	}
+	if o.Packs != nil {
+		m.Packs = o.Packs
+	}
+	for id, v := range o.PackOptions {
+		if m.PackOptions == nil {
+			m.PackOptions = map[string]string{}
+		}
+		m.PackOptions[id] = v
+	}
+}
+
+// Apply copies fields which are set into s.
+func (m *Manifest) Apply(s *Settings) {
+	if m.Name != "" {
+		s.ProjectName = m.Name
+	}
+	if m.Module != "" {
+		s.ModulePath = m.Module
+	}
+	if m.GoVersion != "" {
+		s.GoVersion = m.GoVersion
+	}
+	if m.Logger != "" {
+		s.Logger = m.Logger
+	}
+	if m.Database != "" {
+		s.Database = m.Database
+	}
+	if m.Router != "" {
+		s.Router = m.Router
+	}
+	if m.UseConsul != nil {
+		s.UseConsul = *m.UseConsul
+	}
+	if m.SyncConfigWithConsul != nil {
+		s.SyncConfigWithConsul = *m.SyncConfigWithConsul
+	}
+	if m.UseJaeger != nil {
+		s.UseJaeger = *m.UseJaeger
+	}
+	if m.UsePrometheus != nil {
+		s.UsePrometheus = *m.UsePrometheus
+	}
+	if m.TemplatesDir != "" {
+		s.TemplatesDir = m.TemplatesDir
+	}
+	if m.Packs != nil {
+		s.Packs = m.Packs
+	}
+	for id, v := range m.PackOptions {
+		if s.PackOptions == nil {
+			s.PackOptions = map[string]string{}
+		}
+		s.PackOptions[id] = v
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+// clone returns deep copy of m, changes of the copy don't affect m.
+func (m *Manifest) clone() *Manifest {
+	c := *m
+	for _, p := range []**bool{&c.UseConsul, &c.SyncConfigWithConsul, &c.UseJaeger, &c.UsePrometheus} {
+		if *p != nil {
+			*p = boolPtr(**p)
+		}
+	}
+	if m.Packs != nil {
+		c.Packs = append([]string{}, m.Packs...)
+	}
+	if m.PackOptions != nil {
+		c.PackOptions = make(map[string]string, len(m.PackOptions))
+		for id, v := range m.PackOptions {
+			c.PackOptions[id] = v
+		}
+	}
+	return &c
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// MatrixAxes restricts values of the settings enumerated by Combinations, empty axis means all values.
+type MatrixAxes struct {
+	Loggers              []LoggerChoice
+	Databases            []DBChoice
+	Routers              []RouterChoice
+	UseConsul            []bool
+	SyncConfigWithConsul []bool
+	UseJaeger            []bool
+	UsePrometheus        []bool
+}
+
+var allBools = []bool{false, true}
+
+// Combinations returns settings for every combination of choices allowed by axes,
+// other fields are copied from base. Inconsistent combinations (config sync without consul) are skipped.
+func Combinations(base Settings, axes MatrixAxes) []Settings {
+	loggers, databases, routers := axes.Loggers, axes.Databases, axes.Routers
+	if len(loggers) == 0 {
+		loggers = Loggers
+	}
+	if len(databases) == 0 {
+		databases = Databases
+	}
+	if len(routers) == 0 {
+		routers = Routers
+	}
+	bools := func(v []bool) []bool {
+		if len(v) == 0 {
+			return allBools
+		}
+		return v
+	}
+
+	var res []Settings
+	for _, logger := range loggers {
+		for _, db := range databases {
+			for _, router := range routers {
+				for _, consul := range bools(axes.UseConsul) {
+					for _, sync := range bools(axes.SyncConfigWithConsul) {
+						if sync && !consul {
+							continue
+						}
+						for _, jaeger := range bools(axes.UseJaeger) {
+							for _, prometheus := range bools(axes.UsePrometheus) {
+								s := base
+								s.Logger, s.Database, s.Router = logger, db, router
+								s.UseConsul, s.SyncConfigWithConsul = consul, sync
+								s.UseJaeger, s.UsePrometheus = jaeger, prometheus
+								res = append(res, s)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+// MatrixResult is the outcome of the combination check.
+type MatrixResult struct {
+	Settings Settings
+	// Stage is the failed stage: render, write, gofmt or vet, it's empty if the combination passed.
+	Stage string
+	// Output is the output of the failed stage.
+	Output string
+}
+
+// Passed reports whether all stages passed.
+func (r *MatrixResult) Passed() bool {
+	return r.Stage == ""
+}
+
+// CheckCombination renders the project with settings s into dir, checks formatting of go files and runs go vet
+// with environment env, e.g. GOMODCACHE shared by all combinations.
+func CheckCombination(s Settings, dir string, env []string) MatrixResult {
+	res := MatrixResult{Settings: s}
+	s.ProjectRootDir = dir
+
+	files, err := Render(&s)
+	if err != nil {
+		res.Stage, res.Output = "render", err.Error()
+		return res
+	}
+	if err = writeFiles(dir, files); err != nil {
+		res.Stage, res.Output = "write", err.Error()
+		return res
+	}
+
+	if out, err := runIn(dir, env, "gofmt", "-l", "."); err != nil || len(bytes.TrimSpace(out)) != 0 {
+		res.Stage, res.Output = "gofmt", "not formatted:\n"+string(out)
+		return res
+	}
+	if out, err := runIn(dir, env, "go", "vet", "./..."); err != nil {
+		res.Stage, res.Output = "vet", string(out)
+		return res
+	}
+
+	return res
+}
+
+func runIn(dir string, env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return out, nil
+}
@@ -0,0 +1,260 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PackManifestFile is the name of the template pack manifest in the pack root directory.
+const PackManifestFile = "pack.yml"
+
+// Pack is a set of additional templates in a local directory (or a local git checkout),
+// it declares its files, conditions under which they are rendered and extra questions asked by generate.
+type Pack struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description,omitempty"`
+	Questions   []PackQuestion `yaml:"questions,omitempty"`
+	Files       []PackFile     `yaml:"files"`
+
+	// Dir is the pack root directory.
+	Dir string `yaml:"-"`
+}
+
+// PackQuestion is an extra generate question, the answer is available in pack templates as .Options.<id>.
+// Question with options is a choice, otherwise it's a yes/no question and the answer is bool.
+type PackQuestion struct {
+	ID      string   `yaml:"id"`
+	Prompt  string   `yaml:"prompt"`
+	Options []string `yaml:"options,omitempty"`
+	Default string   `yaml:"default,omitempty"`
+}
+
+// IsYesNo reports whether the question is a yes/no question.
+func (q *PackQuestion) IsYesNo() bool {
+	return len(q.Options) == 0
+}
+
+// answer returns the answer to the question from options or the default answer, ok is false if there is neither.
+func (q *PackQuestion) answer(options map[string]string) (v string, ok bool) {
+	if v, ok = options[q.ID]; ok {
+		return v, true
+	}
+	return q.Default, q.Default != ""
+}
+
+// PackFile describes a file rendered by the pack.
+type PackFile struct {
+	// Template is a path of the template relative to the pack directory, it must be inside the pack directory.
+	Template string `yaml:"template"`
+	// Output is a path of the rendered file relative to the project root, it may use template data, e.g. {{.Name}}.
+	Output string `yaml:"output"`
+	// When is a template condition, the file is rendered if it evaluates to true, empty condition is always true.
+	When string `yaml:"when,omitempty"`
+	// Format makes generator gofmt the rendered file.
+	Format bool `yaml:"format,omitempty"`
+}
+
+// LoadPack reads pack manifest from the pack directory.
+func LoadPack(dir string) (*Pack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("load pack: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	p := &Pack{Dir: dir}
+	if err = dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("pack %s: decode %s: %w", dir, PackManifestFile, err)
+	}
+	if err = p.validate(); err != nil {
+		return nil, fmt.Errorf("pack %s: %w", dir, err)
+	}
+
+	return p, nil
+}
+
+// LoadPacks reads manifests of packs in dirs, question ids must be unique across all packs,
+// because answers to them share the same namespace.
+func LoadPacks(dirs []string) ([]*Pack, error) {
+	packs := make([]*Pack, 0, len(dirs))
+	askedBy := map[string]string{}
+	for _, dir := range dirs {
+		p, err := LoadPack(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range p.Questions {
+			if other, ok := askedBy[q.ID]; ok {
+				return nil, fmt.Errorf("pack %s: %w", dir, &FieldError{
+					Field: "questions.id", Value: q.ID, Reason: "is already asked by pack " + other,
+				})
+			}
+			askedBy[q.ID] = p.Name
+		}
+		packs = append(packs, p)
+	}
+	return packs, nil
+}
+
+func (p *Pack) validate() error {
+	if p.Name == "" {
+		return &FieldError{Field: "name", Reason: "must not be empty"}
+	}
+
+	ids := map[string]bool{}
+	for _, q := range p.Questions {
+		if q.ID == "" {
+			return &FieldError{Field: "questions.id", Reason: "must not be empty"}
+		}
+		if ids[q.ID] {
+			return &FieldError{Field: "questions.id", Value: q.ID, Reason: "must be unique"}
+		}
+		ids[q.ID] = true
+		if q.Default != "" {
+			if err := q.check(q.Default); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range p.Files {
+		if f.Template == "" {
+			return &FieldError{Field: "files.template", Reason: "must not be empty"}
+		}
+		t := path.Clean(filepath.ToSlash(f.Template))
+		if filepath.IsAbs(f.Template) || path.IsAbs(t) || t == ".." || strings.HasPrefix(t, "../") {
+			return &FieldError{Field: "files.template", Value: f.Template, Reason: "must be inside the pack directory"}
+		}
+		if f.Output == "" {
+			return &FieldError{Field: "files.output", Value: f.Template, Reason: "output must not be empty"}
+		}
+	}
+
+	return nil
+}
+
+// Check validates answers to the pack questions, questions without default must be answered.
+func (p *Pack) Check(options map[string]string) error {
+	for _, q := range p.Questions {
+		v, ok := q.answer(options)
+		if !ok {
+			return &FieldError{Field: "pack_options." + q.ID, Reason: "pack " + p.Name + " requires an answer"}
+		}
+		if err := q.check(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *PackQuestion) check(v string) error {
+	if q.IsYesNo() {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return &FieldError{Field: "pack_options." + q.ID, Value: v, Reason: "expected true or false"}
+		}
+		return nil
+	}
+
+	for _, o := range q.Options {
+		if o == v {
+			return nil
+		}
+	}
+	return &FieldError{Field: "pack_options." + q.ID, Value: v, Reason: "expected one of: " + strings.Join(q.Options, ", ")}
+}
+
+// answers converts options to values used in templates, yes/no answers become bool.
+func (p *Pack) answers(options map[string]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(p.Questions))
+	for _, q := range p.Questions {
+		v, _ := q.answer(options)
+		if q.IsYesNo() {
+			res[q.ID], _ = strconv.ParseBool(v)
+			continue
+		}
+		res[q.ID] = v
+	}
+	return res
+}
+
+// templatePath returns path of the pack template on disk, symlinks must not lead outside the pack directory.
+func (p *Pack) templatePath(t string) (string, error) {
+	dir, err := filepath.EvalSymlinks(p.Dir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(p.Dir, filepath.FromSlash(t)))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &FieldError{Field: "files.template", Value: t, Reason: "must be inside the pack directory"}
+	}
+	return resolved, nil
+}
+
+// renderPacks renders files of all packs from settings.
+func (g *generator) renderPacks(data *templateData) error {
+	packs, err := LoadPacks(g.settings.Packs)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range packs {
+		if err = p.Check(g.settings.PackOptions); err != nil {
+			return fmt.Errorf("pack %s: %w", p.Name, err)
+		}
+
+		packData := *data
+		packData.Options = p.answers(g.settings.PackOptions)
+
+		for _, pf := range p.Files {
+			ok, err := g.evalPackCondition(pf.When, &packData)
+			if err != nil {
+				return fmt.Errorf("pack %s: condition of %s: %w", p.Name, pf.Template, err)
+			}
+			if !ok {
+				continue
+			}
+
+			file, err := p.templatePath(pf.Template)
+			if err != nil {
+				return fmt.Errorf("pack %s: %w", p.Name, err)
+			}
+			tpl, err := g.newTemplate(path.Base(file)).ParseFiles(file)
+			if err != nil {
+				return fmt.Errorf("pack %s: %w", p.Name, err)
+			}
+			if err = g.renderFile(tpl, pf.Output, pf.Format, &packData); err != nil {
+				return fmt.Errorf("pack %s: %w", p.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *generator) evalPackCondition(when string, data *templateData) (bool, error) {
+	if strings.TrimSpace(when) == "" {
+		return true, nil
+	}
+
+	tpl, err := g.newTemplate("when").Parse(when)
+	if err != nil {
+		return false, err
+	}
+
+	out := &bytes.Buffer{}
+	if err = tpl.Execute(out, data); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.TrimSpace(out.String()))
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset is a named set of choices for a common service profile.
+type Preset struct {
+	Name        string
+	Description string
+	Manifest    *Manifest
+}
+
+// Presets lists bundled presets, every one of them sets all choices.
+var Presets = []Preset{
+	{
+		Name:        "minimal",
+		Description: "no database, consul, jaeger and prometheus",
+		Manifest: &Manifest{
+			Logger:               GoKit,
+			Database:             NoDb,
+			Router:               GorillaMux,
+			UseConsul:            boolPtr(false),
+			SyncConfigWithConsul: boolPtr(false),
+			UseJaeger:            boolPtr(false),
+			UsePrometheus:        boolPtr(false),
+		},
+	},
+	{
+		Name:        "full-pg",
+		Description: "postgres, consul with config sync, jaeger and prometheus",
+		Manifest: &Manifest{
+			Logger:               GoKit,
+			Database:             Postgresql,
+			Router:               GorillaMux,
+			UseConsul:            boolPtr(true),
+			SyncConfigWithConsul: boolPtr(true),
+			UseJaeger:            boolPtr(true),
+			UsePrometheus:        boolPtr(true),
+		},
+	},
+	{
+		Name:        "full-ch",
+		Description: "clickhouse, consul with config sync, jaeger and prometheus",
+		Manifest: &Manifest{
+			Logger:               GoKit,
+			Database:             Clickhouse,
+			Router:               GorillaMux,
+			UseConsul:            boolPtr(true),
+			SyncConfigWithConsul: boolPtr(true),
+			UseJaeger:            boolPtr(true),
+			UsePrometheus:        boolPtr(true),
+		},
+	},
+}
+
+// FindPreset returns deep copy of the bundled preset manifest, so callers may change it.
+func FindPreset(name string) (*Manifest, error) {
+	names := make([]string, 0, len(Presets))
+	for _, p := range Presets {
+		if p.Name == name {
+			return p.Manifest.clone(), nil
+		}
+		names = append(names, p.Name)
+	}
+	return nil, fmt.Errorf("unknown preset %q, expected one of: %s", name, strings.Join(names, ", "))
+}
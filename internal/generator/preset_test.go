@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+// TestPresetsVerify generates a project from every bundled preset and runs build, vet and tests of it.
+func TestPresetsVerify(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated projects")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain isn't found")
+	}
+
+	for _, p := range Presets {
+		p := p
+		t.Run(p.Name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			s := Settings{ProjectName: "svc", ProjectRootDir: dir, Reporter: discardReporter{}}
+			p.Manifest.Apply(&s)
+			if _, err := Run(context.Background(), &s); err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+
+			cmd := exec.Command("go", "mod", "tidy")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("go mod tidy: %v\n%s", err, out)
+			}
+			if err := Verify(context.Background(), dir, discardReporter{}); err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+		})
+	}
+}
+
+// TestFindPresetCopy checks that changes of the found preset don't leak into the bundled one.
+func TestFindPresetCopy(t *testing.T) {
+	name := Presets[0].Name
+	before, err := yaml.Marshal(Presets[0].Manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := FindPreset(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*bool{m.UseConsul, m.SyncConfigWithConsul, m.UseJaeger, m.UsePrometheus} {
+		if p != nil {
+			*p = !*p
+		}
+	}
+	m.Packs = append(m.Packs, "extra")
+	if m.PackOptions == nil {
+		m.PackOptions = map[string]string{}
+	}
+	m.PackOptions["extra"] = "on"
+
+	after, err := yaml.Marshal(Presets[0].Manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("bundled preset %s is changed through the found copy:\n%s\nwant:\n%s", name, after, before)
+	}
+}
@@ -0,0 +1,248 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/merge"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectManifestFile is the name of the file in the project root which describes how the project was generated.
+const ProjectManifestFile = ".skeleton.yml"
+
+// BaseSnapshotDir keeps copies of the generated files as they were rendered,
+// they are used as the merge base by upgrade.
+const BaseSnapshotDir = ".skeleton/base"
+
+// PendingDir keeps .skeleton.yml and snapshots of the upgrade which left conflicts,
+// they replace the current ones when conflict markers are resolved.
+const PendingDir = ".skeleton/pending"
+
+// pendingConflictsFile lists files with conflicts of the pending upgrade, one path per line.
+const pendingConflictsFile = PendingDir + "/conflicts"
+
+// ProjectManifest records generator version, settings and generated files of the project.
+type ProjectManifest struct {
+	Version  string         `yaml:"version"`
+	Settings *Manifest      `yaml:"settings"`
+	Files    []FileChecksum `yaml:"files"`
+}
+
+// FileChecksum is sha256 checksum of the generated file content, path is relative to the project root.
+type FileChecksum struct {
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// ReadProjectManifest reads .skeleton.yml from the project root directory.
+func ReadProjectManifest(rootDir string) (*ProjectManifest, error) {
+	data, err := os.ReadFile(filepath.Join(rootDir, ProjectManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var pm ProjectManifest
+	if err = yaml.Unmarshal(data, &pm); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", ProjectManifestFile, err)
+	}
+	if pm.Settings == nil {
+		return nil, fmt.Errorf("%s: settings are missing", ProjectManifestFile)
+	}
+	// the go version may be older than MinGoVersion if the project was generated before it was raised,
+	// upgrade, add and remove raise it.
+	if err = pm.Settings.validate(checkStoredGoVersion); err != nil {
+		return nil, fmt.Errorf("%s: %w", ProjectManifestFile, err)
+	}
+
+	return &pm, nil
+}
+
+// NewProjectManifest returns manifest of the project generated with settings s.
+func NewProjectManifest(s *Settings, files []File) *ProjectManifest {
+	pm := &ProjectManifest{Version: SkeletonVersion(), Settings: NewManifest(s)}
+	for _, f := range files {
+		pm.Files = append(pm.Files, FileChecksum{Path: f.Path, SHA256: checksum(f.Content)})
+	}
+	return pm
+}
+
+// Marshal returns content of .skeleton.yml.
+func (pm *ProjectManifest) Marshal() ([]byte, error) {
+	buff := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buff)
+	enc.SetIndent(2)
+	if err := enc.Encode(pm); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// Write stores manifest in .skeleton.yml of the project root directory.
+func (pm *ProjectManifest) Write(rootDir string) error {
+	data, err := pm.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(rootDir, ProjectManifestFile), data, 0644)
+}
+
+// writeProjectManifest stores .skeleton.yml and snapshots of the rendered files in the rootDir.
+func writeProjectManifest(rootDir string, s *Settings, files []File, r Reporter) error {
+	if err := os.RemoveAll(filepath.Join(rootDir, filepath.FromSlash(BaseSnapshotDir))); err != nil {
+		return err
+	}
+
+	mf, err := manifestFiles(s, files)
+	if err != nil {
+		return err
+	}
+	for _, f := range mf {
+		if f.Path == ProjectManifestFile {
+			r.File(f.Path, len(f.Content), false)
+		}
+	}
+	return writeFiles(rootDir, mf)
+}
+
+// manifestFiles returns .skeleton.yml and snapshots of the rendered files.
+func manifestFiles(s *Settings, files []File) ([]File, error) {
+	return versionManifestFiles(SkeletonVersion(), s, files)
+}
+
+// versionManifestFiles returns .skeleton.yml recording files as rendered by templates of the skeleton version
+// and snapshots of the files.
+func versionManifestFiles(version string, s *Settings, files []File) ([]File, error) {
+	pm := NewProjectManifest(s, files)
+	pm.Version = version
+	data, err := pm.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]File, 0, len(files)+1)
+	for _, f := range files {
+		res = append(res, File{Path: path.Join(BaseSnapshotDir, f.Path), Content: f.Content})
+	}
+	return append(res, File{Path: ProjectManifestFile, Content: data}), nil
+}
+
+// pendingFiles moves manifest files mf into PendingDir and adds the list of conflicts.
+func pendingFiles(mf []File, conflicts []string) []File {
+	res := make([]File, 0, len(mf)+1)
+	for _, f := range mf {
+		p := path.Join(PendingDir, f.Path)
+		if strings.HasPrefix(f.Path, BaseSnapshotDir+"/") {
+			p = path.Join(PendingDir, "base", strings.TrimPrefix(f.Path, BaseSnapshotDir+"/"))
+		}
+		res = append(res, File{Path: p, Content: f.Content})
+	}
+	return append(res, File{Path: pendingConflictsFile, Content: []byte(strings.Join(conflicts, "\n") + "\n")})
+}
+
+// finishPending replaces .skeleton.yml and snapshots with pending ones if the previous upgrade left conflicts
+// which are resolved now, it fails if some files still have conflict markers.
+func finishPending(ctx context.Context, rootDir string) error {
+	data, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(pendingConflictsFile)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var unresolved []string
+	for _, p := range strings.Fields(string(data)) {
+		content, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(p)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if merge.HasConflicts(content) {
+			unresolved = append(unresolved, p)
+		}
+	}
+	if len(unresolved) != 0 {
+		return fmt.Errorf("previous upgrade left conflict markers in %s, resolve them first", strings.Join(unresolved, ", "))
+	}
+
+	pending, err := listFiles(rootDir, PendingDir)
+	if err != nil {
+		return err
+	}
+	snapshots, err := listFiles(rootDir, BaseSnapshotDir)
+	if err != nil {
+		return err
+	}
+
+	var files []File
+	for _, p := range pending {
+		rel := strings.TrimPrefix(p, PendingDir+"/")
+		switch {
+		case rel == ProjectManifestFile:
+		case strings.HasPrefix(rel, "base/"):
+			rel = path.Join(BaseSnapshotDir, strings.TrimPrefix(rel, "base/"))
+		default:
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(p)))
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Path: rel, Content: content})
+	}
+
+	return writeChanges(ctx, rootDir, files, append(pending, snapshots...))
+}
+
+// listFiles returns slash separated paths relative to rootDir of all files in dir of the project.
+func listFiles(rootDir, dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(filepath.Join(rootDir, filepath.FromSlash(dir)), func(p string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(rootDir, p)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	return paths, err
+}
+
+// readBaseSnapshot returns content of the file as it was generated, ok is false if snapshot doesn't exist.
+func readBaseSnapshot(rootDir, path string) (content []byte, ok bool, err error) {
+	content, err = os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(BaseSnapshotDir), filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	return content, err == nil, err
+}
+
+// Checksum returns recorded checksum of the file or empty string if file is not recorded.
+func (pm *ProjectManifest) Checksum(path string) string {
+	for _, f := range pm.Files {
+		if f.Path == path {
+			return f.SHA256
+		}
+	}
+	return ""
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
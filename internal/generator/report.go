@@ -0,0 +1,317 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reporter receives progress of Run, Vendor and Verify. Paths are slash separated and relative to the project root.
+type Reporter interface {
+	// Step reports start of the generation stage, e.g. "download dependencies".
+	Step(name string)
+	// Dir reports the directory created in the project.
+	Dir(path string)
+	// File reports the file written into the project, skipped is set if existing file was kept.
+	File(path string, size int, skipped bool)
+	// Command reports the finished external command.
+	Command(c *CommandResult)
+	// Done reports the generated project.
+	Done(s *Summary)
+}
+
+// CommandResult describes the external command run by the generator.
+type CommandResult struct {
+	Args []string
+	Dir  string
+	// Output is combined stdout and stderr.
+	Output string
+	Stderr string
+	// ExitCode is -1 if the command wasn't started.
+	ExitCode int
+	Duration time.Duration
+	Err      error
+}
+
+// String returns the command line.
+func (c *CommandResult) String() string {
+	return strings.Join(c.Args, " ")
+}
+
+// Summary describes the generated project.
+type Summary struct {
+	// RootDir is the project directory or the archive the project is written to.
+	RootDir   string
+	Settings  *Manifest
+	Files     []string
+	NextSteps []string
+}
+
+// newSummary returns summary of the project, files are the ones written by Run.
+func newSummary(s *Settings, files []File) *Summary {
+	sum := &Summary{RootDir: s.ProjectRootDir, Settings: NewManifest(s)}
+	for _, f := range files {
+		sum.Files = append(sum.Files, f.Path)
+	}
+
+	enter := ""
+	if s.ProjectRootDir != "" && s.ProjectRootDir != "." {
+		enter = "cd " + s.ProjectRootDir
+	}
+	sum.NextSteps = NextSteps(enter, s.WithDeps)
+	return sum
+}
+
+// NextSteps returns commands to run after generation, enter is the command getting into the project directory,
+// it's skipped if empty. Dependencies are downloaded first unless withDeps is set.
+func NextSteps(enter string, withDeps bool) []string {
+	var steps []string
+	if enter != "" {
+		steps = append(steps, enter)
+	}
+	if !withDeps {
+		steps = append(steps, "go mod tidy", "go mod vendor")
+	}
+	return append(steps, "make lint", "make test")
+}
+
+// CommandError is returned when the external command fails, it carries the exit status and stderr of the command.
+type CommandError struct {
+	*CommandResult
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.CommandResult, e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += "\n" + stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// runCommand runs the command in dir and reports it, the failure is returned as *CommandError.
+// The command is killed when ctx is done, ctx.Err() is returned then.
+func runCommand(ctx context.Context, r Reporter, dir string, args ...string) error {
+	res := &CommandResult{Args: args, Dir: dir}
+
+	out := &commandOutput{}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = outputStream{out: out}, outputStream{out: out, stderr: true}
+
+	start := time.Now()
+	res.Err = cmd.Run()
+	res.Duration = time.Since(start)
+	res.Output, res.Stderr = out.combined.String(), out.stderr.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(res.Err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	case res.Err != nil:
+		res.ExitCode = -1
+	}
+	r.Command(res)
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if res.Err != nil {
+		return &CommandError{CommandResult: res}
+	}
+	return nil
+}
+
+// commandOutput collects the combined output of the command and a copy of its stderr.
+// os/exec copies stdout and stderr in separate goroutines, so writes are serialised by mu.
+type commandOutput struct {
+	mu       sync.Mutex
+	combined bytes.Buffer
+	stderr   bytes.Buffer
+}
+
+// outputStream is stdout or stderr of the command writing into commandOutput.
+type outputStream struct {
+	out    *commandOutput
+	stderr bool
+}
+
+func (s outputStream) Write(p []byte) (int, error) {
+	s.out.mu.Lock()
+	defer s.out.mu.Unlock()
+
+	if s.stderr {
+		s.out.stderr.Write(p)
+	}
+	return s.out.combined.Write(p)
+}
+
+// Verbosity is the level of details printed by the text reporter.
+type Verbosity int
+
+const (
+	// Quiet prints only output of failed commands.
+	Quiet Verbosity = iota
+	// Normal prints generation stages and files.
+	Normal
+	// Verbose prints also directories and all commands with their output.
+	Verbose
+)
+
+// TextReporter prints progress as log lines.
+type TextReporter struct {
+	log       *log.Logger
+	verbosity Verbosity
+}
+
+// NewTextReporter returns reporter writing into w.
+func NewTextReporter(w io.Writer, verbosity Verbosity) *TextReporter {
+	return &TextReporter{log: log.New(w, "", log.LstdFlags), verbosity: verbosity}
+}
+
+// defaultReporter is used when Settings have no reporter.
+func defaultReporter() Reporter {
+	return NewTextReporter(os.Stderr, Normal)
+}
+
+func (t *TextReporter) Step(name string) {
+	if t.verbosity >= Normal {
+		t.log.Print(name + " ...")
+	}
+}
+
+func (t *TextReporter) Dir(path string) {
+	if t.verbosity >= Verbose {
+		t.log.Printf("create directory %s ...", path)
+	}
+}
+
+func (t *TextReporter) File(path string, size int, skipped bool) {
+	switch {
+	case t.verbosity < Normal:
+	case skipped:
+		t.log.Printf("skip existing %s ...", path)
+	case t.verbosity >= Verbose:
+		t.log.Printf("create %s (%d B) ...", path, size)
+	default:
+		t.log.Printf("create %s ...", path)
+	}
+}
+
+func (t *TextReporter) Command(c *CommandResult) {
+	if c.Err == nil && t.verbosity < Verbose {
+		return
+	}
+	t.log.Printf("%s: exit status %d in %s", c, c.ExitCode, c.Duration.Round(time.Millisecond))
+	// output written only to stderr is shown by the returned CommandError.
+	if c.Err != nil && t.verbosity < Verbose && c.Output == c.Stderr {
+		return
+	}
+	if out := strings.TrimRight(c.Output, "\n"); out != "" {
+		t.log.Writer().Write([]byte(out + "\n"))
+	}
+}
+
+func (t *TextReporter) Done(s *Summary) {
+	if t.verbosity < Normal {
+		return
+	}
+	t.log.Print("DONE!")
+	if len(s.NextSteps) != 0 {
+		t.log.Print("next steps:")
+		for _, step := range s.NextSteps {
+			t.log.Writer().Write([]byte("    " + step + "\n"))
+		}
+	}
+}
+
+// JSONReporter writes every event as a JSON object on a separate line.
+type JSONReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONReporter returns reporter writing into w.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{enc: json.NewEncoder(w)}
+}
+
+// Event is a JSON line written by JSONReporter, Type is step, dir, file, command, summary or error.
+type Event struct {
+	Type       string    `json:"event"`
+	Name       string    `json:"name,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Size       *int      `json:"size,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	Command    string    `json:"command,omitempty"`
+	Dir        string    `json:"dir,omitempty"`
+	ExitCode   *int      `json:"exit_code,omitempty"`
+	DurationMS *int64    `json:"duration_ms,omitempty"`
+	Output     string    `json:"output,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	RootDir    string    `json:"root_dir,omitempty"`
+	Settings   *Manifest `json:"settings,omitempty"`
+	Files      []string  `json:"files,omitempty"`
+	NextSteps  []string  `json:"next_steps,omitempty"`
+}
+
+func (j *JSONReporter) emit(e *Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.enc.Encode(e)
+}
+
+func (j *JSONReporter) Step(name string) {
+	j.emit(&Event{Type: "step", Name: name})
+}
+
+func (j *JSONReporter) Dir(path string) {
+	j.emit(&Event{Type: "dir", Path: path})
+}
+
+func (j *JSONReporter) File(path string, size int, skipped bool) {
+	action := "create"
+	if skipped {
+		action = "skip"
+	}
+	j.emit(&Event{Type: "file", Path: path, Size: &size, Action: action})
+}
+
+func (j *JSONReporter) Command(c *CommandResult) {
+	ms := c.Duration.Milliseconds()
+	e := &Event{Type: "command", Command: c.String(), Dir: c.Dir, ExitCode: &c.ExitCode, DurationMS: &ms, Output: c.Output}
+	if c.Err != nil {
+		e.Error = c.Err.Error()
+	}
+	j.emit(e)
+}
+
+func (j *JSONReporter) Done(s *Summary) {
+	j.emit(&Event{Type: "summary", RootDir: s.RootDir, Settings: s.Settings, Files: s.Files, NextSteps: s.NextSteps})
+}
+
+// Error reports the error which stopped the generation.
+func (j *JSONReporter) Error(err error) {
+	j.emit(&Event{Type: "error", Error: err.Error()})
+}
+
+// discardReporter drops all events.
+type discardReporter struct{}
+
+func (discardReporter) Step(string)            {}
+func (discardReporter) Dir(string)             {}
+func (discardReporter) File(string, int, bool) {}
+func (discardReporter) Command(*CommandResult) {}
+func (discardReporter) Done(*Summary)          {}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestRunCommandOutput runs a command writing into both streams, run it with -race.
+func TestRunCommandOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh isn't found")
+	}
+
+	const n = 500
+	script := `i=0; while [ $i -lt 500 ]; do echo "out $i"; echo "err $i" >&2; i=$((i+1)); done; exit 3`
+	err := runCommand(context.Background(), discardReporter{}, t.TempDir(), "sh", "-c", script)
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("runCommand() = %v, want *CommandError", err)
+	}
+	if cmdErr.ExitCode != 3 {
+		t.Errorf("exit code = %d, want 3", cmdErr.ExitCode)
+	}
+
+	outLines, errLines := 0, 0
+	for _, line := range strings.Split(strings.TrimSuffix(cmdErr.Output, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "out "):
+			outLines++
+		case strings.HasPrefix(line, "err "):
+			errLines++
+		default:
+			t.Fatalf("corrupted line %q in the combined output", line)
+		}
+	}
+	if outLines != n || errLines != n {
+		t.Errorf("combined output has %d stdout and %d stderr lines, want %d of each", outLines, errLines, n)
+	}
+
+	stderr := strings.Split(strings.TrimSuffix(cmdErr.Stderr, "\n"), "\n")
+	if len(stderr) != n {
+		t.Fatalf("stderr has %d lines, want %d", len(stderr), n)
+	}
+	for _, line := range stderr {
+		if !strings.HasPrefix(line, "err ") {
+			t.Fatalf("unexpected line %q in stderr", line)
+		}
+	}
+}
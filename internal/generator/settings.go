@@ -1,30 +1,115 @@
 package generator
 
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
 type LoggerChoice string
 
 const (
-	GoKit LoggerChoice = "Go Kit"
-	Zap   LoggerChoice = "Zap"
+	GoKit LoggerChoice = "gokit"
+	Zap   LoggerChoice = "zap"
 )
 
+// Loggers lists all supported loggers, first one is default.
+var Loggers = []LoggerChoice{GoKit, Zap}
+
+// Title returns human-readable logger name.
+func (l LoggerChoice) Title() string {
+	switch l {
+	case GoKit:
+		return "Go Kit"
+	case Zap:
+		return "Zap"
+	default:
+		return string(l)
+	}
+}
+
+func (l LoggerChoice) valid() bool {
+	for _, v := range Loggers {
+		if v == l {
+			return true
+		}
+	}
+	return false
+}
+
 type DBChoice string
 
 const (
-	NoDb       DBChoice = "No database"
-	Clickhouse DBChoice = "Clickhouse"
-	Postgresql DBChoice = "Postgres"
+	NoDb       DBChoice = "none"
+	Clickhouse DBChoice = "clickhouse"
+	Postgresql DBChoice = "postgres"
 )
 
+// Databases lists all supported databases, first one is default.
+var Databases = []DBChoice{NoDb, Clickhouse, Postgresql}
+
+// Title returns human-readable database name.
+func (d DBChoice) Title() string {
+	switch d {
+	case NoDb:
+		return "No database"
+	case Clickhouse:
+		return "Clickhouse"
+	case Postgresql:
+		return "Postgres"
+	default:
+		return string(d)
+	}
+}
+
+func (d DBChoice) valid() bool {
+	for _, v := range Databases {
+		if v == d {
+			return true
+		}
+	}
+	return false
+}
+
 type RouterChoice string
 
 const (
-	GorillaMux RouterChoice = "Gorilla mux"
-	GIN        RouterChoice = "GIN"
+	GorillaMux RouterChoice = "gorilla-mux"
+	GIN        RouterChoice = "gin"
 )
 
+// Routers lists all supported routers, first one is default.
+var Routers = []RouterChoice{GorillaMux, GIN}
+
+// Title returns human-readable router name.
+func (r RouterChoice) Title() string {
+	switch r {
+	case GorillaMux:
+		return "Gorilla mux"
+	case GIN:
+		return "GIN"
+	default:
+		return string(r)
+	}
+}
+
+func (r RouterChoice) valid() bool {
+	for _, v := range Routers {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
 type Settings struct {
-	ProjectName          string
-	ProjectRootDir       string
+	// ProjectName is the name of cmd/<name> directory, binary and service.
+	ProjectName string
+	// ModulePath is the go module path, project name is used if it's empty.
+	ModulePath     string
+	ProjectRootDir string
+	// GoVersion is the go version of the project, MinGoVersion is used if it's empty.
+	GoVersion            string
 	Logger               LoggerChoice
 	Database             DBChoice
 	Router               RouterChoice
@@ -32,6 +117,192 @@ type Settings struct {
 	SyncConfigWithConsul bool
 	UseJaeger            bool
 	UsePrometheus        bool
+	// TemplatesDir has templates which override embedded assets with the same name.
+	TemplatesDir string
+	// Packs are directories of template packs, PackOptions are answers to their questions.
+	Packs       []string
+	PackOptions map[string]string
 
 	WithDeps bool
+	// Force allows Run to overwrite existing files.
+	Force bool
+	// SkipExisting makes Run to write only files which don't exist yet.
+	SkipExisting bool
+	// Reporter receives progress of Run, it prints log lines into stderr if it's nil.
+	Reporter Reporter
+}
+
+func (s *Settings) reporter() Reporter {
+	if s.Reporter != nil {
+		return s.Reporter
+	}
+	return defaultReporter()
+}
+
+// Module returns the go module path of the project.
+func (s *Settings) Module() string {
+	if s.ModulePath != "" {
+		return s.ModulePath
+	}
+	return s.ProjectName
+}
+
+// Go returns the go version of the project.
+func (s *Settings) Go() string {
+	if s.GoVersion != "" {
+		return s.GoVersion
+	}
+	return MinGoVersion
+}
+
+// Validate checks that all choices are set and consistent.
+func (s *Settings) Validate() error {
+	if s.ProjectName == "" {
+		return &FieldError{Field: "name", Reason: "must not be empty"}
+	}
+	if err := checkName(s.ProjectName); err != nil {
+		return err
+	}
+	if err := checkModulePath(s.Module()); err != nil {
+		return err
+	}
+	if err := checkGoVersion(s.Go()); err != nil {
+		return err
+	}
+	if !s.Logger.valid() {
+		return &FieldError{Field: "logger", Value: s.Logger, Reason: "expected one of: " + join(Loggers)}
+	}
+	if !s.Database.valid() {
+		return &FieldError{Field: "db", Value: s.Database, Reason: "expected one of: " + join(Databases)}
+	}
+	if !s.Router.valid() {
+		return &FieldError{Field: "router", Value: s.Router, Reason: "expected one of: " + join(Routers)}
+	}
+	if s.SyncConfigWithConsul && !s.UseConsul {
+		return &FieldError{Field: "consul_sync_config", Value: true, Reason: "requires consul"}
+	}
+	if s.TemplatesDir != "" {
+		if fi, err := os.Stat(s.TemplatesDir); err != nil || !fi.IsDir() {
+			return &FieldError{Field: "templates_dir", Value: s.TemplatesDir, Reason: "must be an existing directory"}
+		}
+	}
+	if s.Force && s.SkipExisting {
+		return &FieldError{Field: "force", Value: true, Reason: "can't be used together with skip existing"}
+	}
+
+	return nil
+}
+
+// checkName checks that the name may be used as a directory, binary and service name.
+func checkName(name string) error {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case i != 0 && (r == '-' || r == '_' || r == '.'):
+		default:
+			return &FieldError{Field: "name", Value: name, Reason: "must be letters, digits, '-', '_' or '.' starting with a letter or digit"}
+		}
+	}
+	return nil
+}
+
+// checkModulePath checks that the path is a valid import path, e.g. gitlab.company.local/platform/orders.
+func checkModulePath(path string) error {
+	if path == "" {
+		return &FieldError{Field: "module", Reason: "must not be empty"}
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" {
+			return &FieldError{Field: "module", Value: path, Reason: "must not have empty path elements or leading and trailing slashes"}
+		}
+		if elem[0] == '.' || elem[len(elem)-1] == '.' {
+			return &FieldError{Field: "module", Value: path, Reason: "path elements must not start or end with a dot"}
+		}
+		for _, r := range elem {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			case strings.ContainsRune("-._~", r):
+			default:
+				return &FieldError{Field: "module", Value: path, Reason: fmt.Sprintf("invalid char %q", r)}
+			}
+		}
+	}
+	if first := strings.Split(path, "/")[0]; strings.HasPrefix(first, "-") {
+		return &FieldError{Field: "module", Value: path, Reason: "must not start with a dash"}
+	}
+	return nil
+}
+
+// FieldError describes invalid value of the settings field.
+type FieldError struct {
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, fmt.Sprint(e.Value), e.Reason)
+}
+
+// ParseLoggerChoice converts command line value (gokit, zap) to LoggerChoice.
+func ParseLoggerChoice(v string) (LoggerChoice, error) {
+	switch l := LoggerChoice(strings.ToLower(v)); l {
+	case "go-kit":
+		return GoKit, nil
+	default:
+		if !l.valid() {
+			return "", &FieldError{Field: "logger", Value: v, Reason: "expected one of: " + join(Loggers)}
+		}
+		return l, nil
+	}
+}
+
+// ParseDBChoice converts command line value (none, clickhouse, postgres) to DBChoice.
+func ParseDBChoice(v string) (DBChoice, error) {
+	switch d := DBChoice(strings.ToLower(v)); d {
+	case "no":
+		return NoDb, nil
+	case "postgresql":
+		return Postgresql, nil
+	default:
+		if !d.valid() {
+			return "", &FieldError{Field: "db", Value: v, Reason: "expected one of: " + join(Databases)}
+		}
+		return d, nil
+	}
+}
+
+// ParseRouterChoice converts command line value (gorilla-mux, gin) to RouterChoice.
+func ParseRouterChoice(v string) (RouterChoice, error) {
+	switch r := RouterChoice(strings.ToLower(v)); r {
+	case "mux", "gokit":
+		return GorillaMux, nil
+	default:
+		if !r.valid() {
+			return "", &FieldError{Field: "router", Value: v, Reason: "expected one of: " + join(Routers)}
+		}
+		return r, nil
+	}
+}
+
+func join(choices interface{}) string {
+	var values []string
+	switch c := choices.(type) {
+	case []LoggerChoice:
+		for _, v := range c {
+			values = append(values, string(v))
+		}
+	case []DBChoice:
+		for _, v := range c {
+			values = append(values, string(v))
+		}
+	case []RouterChoice:
+		for _, v := range c {
+			values = append(values, string(v))
+		}
+	}
+	return strings.Join(values, ", ")
 }
@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// staging is a hidden temporary directory inside the project root where the project is assembled,
+// so files are moved into place by rename within one file system even if the root is a mount point.
+// commit moves staged files into the project root, rollback removes everything commit created
+// and restores overwritten files, so failed generation leaves no trace.
+type staging struct {
+	rootDir string
+	// createdRoot is set if the project root was created for the staging.
+	createdRoot bool
+	// dir keeps the staged project, backupDir keeps files replaced by commit.
+	tmpDir, dir, backupDir string
+	// exclude are staged files which must not be moved into the project root.
+	exclude map[string]bool
+	// removed are project files, relative to the root, which commit moves into the backup.
+	removed []string
+	// created are paths created in the project root by commit, in creation order.
+	created []string
+	// backups maps replaced project files to their backups.
+	backups map[string]string
+}
+
+func newStaging(rootDir string) (*staging, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	createdRoot := false
+	if _, err = os.Stat(rootDir); os.IsNotExist(err) {
+		if err = os.MkdirAll(rootDir, 0755); err != nil {
+			return nil, err
+		}
+		createdRoot = true
+	}
+
+	tmpDir, err := os.MkdirTemp(rootDir, ".skeleton-staging-")
+	if err != nil {
+		if createdRoot {
+			_ = os.Remove(rootDir)
+		}
+		return nil, err
+	}
+
+	s := &staging{
+		rootDir:     rootDir,
+		createdRoot: createdRoot,
+		tmpDir:      tmpDir,
+		dir:         filepath.Join(tmpDir, "project"),
+		backupDir:   filepath.Join(tmpDir, "backup"),
+		exclude:     map[string]bool{},
+		backups:     map[string]string{},
+	}
+	if err = os.Mkdir(s.dir, 0755); err == nil {
+		err = os.Mkdir(s.backupDir, 0755)
+	}
+	if err != nil {
+		s.rollback()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// copyFromRoot copies existing project file into staging, the copy isn't moved back by commit.
+func (s *staging) copyFromRoot(path string) error {
+	data, err := os.ReadFile(filepath.Join(s.rootDir, filepath.FromSlash(path)))
+	if err != nil {
+		return err
+	}
+
+	p := filepath.Join(s.dir, filepath.FromSlash(path))
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	s.exclude[p] = true
+
+	return os.WriteFile(p, data, 0644)
+}
+
+// remove makes commit delete the project file, rollback restores it.
+func (s *staging) remove(path string) {
+	s.removed = append(s.removed, path)
+}
+
+// commit removes files marked by remove and moves staged files into the project root.
+// It fails with ctx.Err() if ctx is done before or during the commit, so the caller rolls back.
+func (s *staging) commit(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, rel := range s.removed {
+		if err := s.backup(filepath.Join(s.rootDir, filepath.FromSlash(rel)), filepath.FromSlash(rel)); err != nil {
+			return err
+		}
+	}
+
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == s.dir || s.exclude[p] {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(s.rootDir, rel)
+
+		if d.IsDir() {
+			err = os.Mkdir(target, 0755)
+			switch {
+			case os.IsExist(err):
+				return nil
+			case err != nil:
+				return err
+			}
+			s.created = append(s.created, target)
+			return nil
+		}
+
+		if err = s.backup(target, rel); err != nil {
+			return err
+		}
+		if err = os.Rename(p, target); err != nil {
+			return err
+		}
+		s.created = append(s.created, target)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// backup moves existing project file target into the backup directory, rel is its path relative to the root.
+func (s *staging) backup(target, rel string) error {
+	if _, err := os.Lstat(target); os.IsNotExist(err) {
+		return nil
+	}
+	if _, ok := s.backups[target]; ok {
+		return nil
+	}
+
+	backup := filepath.Join(s.backupDir, rel)
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(target, backup); err != nil {
+		return err
+	}
+	s.backups[target] = backup
+	return nil
+}
+
+// rollback removes everything created by commit, restores replaced files and removes staging directory
+// with the project root if it was created for the staging.
+func (s *staging) rollback() {
+	for i := len(s.created) - 1; i >= 0; i-- {
+		_ = os.Remove(s.created[i])
+	}
+	s.created = nil
+	for target, backup := range s.backups {
+		_ = os.Rename(backup, target)
+	}
+	s.backups = map[string]string{}
+	_ = os.RemoveAll(s.tmpDir)
+	if s.createdRoot {
+		_ = os.Remove(s.rootDir)
+	}
+}
+
+// cleanup removes staging directory and forgets what commit did, so later rollback keeps the committed project.
+func (s *staging) cleanup() {
+	s.created, s.backups, s.createdRoot = nil, map[string]string{}, false
+	_ = os.RemoveAll(s.tmpDir)
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunCancelled checks that cancelled Run leaves the project directory as it was and doesn't exit.
+func TestRunCancelled(t *testing.T) {
+	dir := t.TempDir()
+	readme := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(readme, []byte("keep\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := &Settings{ProjectName: "svc", ProjectRootDir: dir, Logger: Zap, Database: NoDb, Router: GIN,
+		Force: true, Reporter: discardReporter{}}
+	if _, err := Run(ctx, s); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() = %v, want context.Canceled", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "README.md" {
+		t.Errorf("project directory has %d entries, want only README.md", len(entries))
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "keep\n" {
+		t.Errorf("README.md = %q, want it restored", data)
+	}
+
+	// the root created for the run is removed as well.
+	missing := filepath.Join(t.TempDir(), "svc")
+	s.ProjectRootDir = missing
+	if _, err = Run(ctx, s); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() = %v, want context.Canceled", err)
+	}
+	if _, err = os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("project root created by the cancelled run exists: %v", err)
+	}
+}
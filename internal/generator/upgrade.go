@@ -0,0 +1,321 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/merge"
+	"os"
+	"path/filepath"
+)
+
+// UpgradeAction describes what upgrade did with the project file.
+type UpgradeAction string
+
+const (
+	// Unchanged file already has the new content.
+	Unchanged UpgradeAction = "unchanged"
+	// Created file is new in templates.
+	Created UpgradeAction = "created"
+	// Updated file wasn't edited by hand and is replaced by the new version.
+	Updated UpgradeAction = "updated"
+	// Merged file has both hand edits and template changes.
+	Merged UpgradeAction = "merged"
+	// Conflict file has conflict markers which must be resolved by hand.
+	Conflict UpgradeAction = "conflict"
+	// Kept file is edited by hand and has no template changes or is no longer generated.
+	Kept UpgradeAction = "kept"
+	// Skipped file was deleted by hand and isn't restored.
+	Skipped UpgradeAction = "skipped"
+	// Deleted file is no longer generated and wasn't edited by hand.
+	Deleted UpgradeAction = "deleted"
+)
+
+// UpgradeResult is the upgrade outcome for a single file.
+type UpgradeResult struct {
+	Path   string
+	Action UpgradeAction
+	// Conflicts is the number of conflicting hunks.
+	Conflicts int
+}
+
+// UpgradeReport lists upgrade outcome for every file known to the generator.
+type UpgradeReport struct {
+	FromVersion string
+	ToVersion   string
+	Files       []UpgradeResult
+	// Upgraded are files merged with templates of ToVersion instead of FromVersion by add or remove,
+	// because the component change overlaps template changes made since the project was generated.
+	Upgraded []string
+	// RaisedGoVersion is the stored go version older than MinGoVersion, the project is moved to MinGoVersion.
+	RaisedGoVersion string
+}
+
+// Conflicts returns files which have conflict markers.
+func (r *UpgradeReport) Conflicts() []UpgradeResult {
+	var res []UpgradeResult
+	for _, f := range r.Files {
+		if f.Action == Conflict {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// Upgrade re-renders templates of the project in rootDir with settings stored in .skeleton.yml
+// and merges them with the current files, using snapshots of the originally generated files as the merge base.
+// Fields set in override replace stored settings.
+func Upgrade(ctx context.Context, rootDir string, override *Manifest) (*UpgradeReport, error) {
+	return upgradeProject(ctx, rootDir, override, nil)
+}
+
+// rewriteFunc changes content of the project file before it's merged, it's applied to both current
+// and base versions, so the change isn't treated as a hand edit.
+type rewriteFunc func(path string, content []byte) []byte
+
+func upgradeProject(ctx context.Context, rootDir string, override *Manifest, rewrite rewriteFunc) (*UpgradeReport, error) {
+	if err := finishPending(ctx, rootDir); err != nil {
+		return nil, err
+	}
+	pm, err := ReadProjectManifest(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &Settings{ProjectRootDir: rootDir}
+	pm.Settings.Apply(settings)
+	if override != nil {
+		override.Apply(settings)
+	}
+	// the go version given explicitly isn't raised, Validate reports it if it's too old.
+	var raisedFrom string
+	if override == nil || override.GoVersion == "" {
+		raisedFrom = raiseGoVersion(settings)
+	}
+	if err = settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	report, err := upgrade(ctx, settings, pm, rewrite)
+	if report != nil {
+		report.RaisedGoVersion = raisedFrom
+	}
+	return report, err
+}
+
+// upgrade brings the project generated with pm to the state rendered by settings.
+func upgrade(ctx context.Context, settings *Settings, pm *ProjectManifest, rewrite rewriteFunc) (*UpgradeReport, error) {
+	g := generator{settings: settings}
+	files, err := g.render()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UpgradeReport{FromVersion: pm.Version, ToVersion: SkeletonVersion()}
+	labels := merge.Labels{
+		Ours:   "current",
+		Base:   "skeleton " + pm.Version,
+		Theirs: "skeleton " + report.ToVersion,
+	}
+
+	// files rendered by current templates with stored settings are the merge base
+	// if they are the same as at generation time and snapshots are missing.
+	old := &Settings{ProjectRootDir: settings.ProjectRootDir}
+	pm.Settings.Apply(old)
+	oldFiles := map[string][]byte{}
+	if rendered, err := Render(old); err == nil {
+		for _, f := range rendered {
+			oldFiles[f.Path] = f.Content
+		}
+	}
+
+	mf, err := manifestFiles(settings, files)
+	if err != nil {
+		return nil, fmt.Errorf("render project manifest: %w", err)
+	}
+	return report, mergeProject(ctx, settings.ProjectRootDir, pm, files, oldFiles, mf, labels, rewrite, report)
+}
+
+// mergeProject merges files into the project generated with pm and writes the result at once,
+// on any error the project is left as it was. mf are .skeleton.yml and snapshots of files, they replace
+// the current ones only if no conflicts are left, otherwise they are kept in PendingDir.
+func mergeProject(ctx context.Context, rootDir string, pm *ProjectManifest, files []File, oldFiles map[string][]byte, mf []File,
+	labels merge.Labels, rewrite rewriteFunc, report *UpgradeReport) error {
+	var (
+		changes []File
+		removed []string
+	)
+
+	rendered := make(map[string]bool, len(files))
+	for _, f := range files {
+		rendered[f.Path] = true
+
+		res, merged, err := upgradeFile(rootDir, pm, f, oldFiles[f.Path], labels, rewrite)
+		if err != nil {
+			return fmt.Errorf("upgrade %s: %w", f.Path, err)
+		}
+		report.Files = append(report.Files, res)
+		if merged != nil {
+			changes = append(changes, File{Path: f.Path, Content: merged})
+		}
+	}
+
+	for _, old := range pm.Files {
+		if rendered[old.Path] {
+			continue
+		}
+
+		current, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(old.Path)))
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return err
+		case checksum(current) != old.SHA256:
+			report.Files = append(report.Files, UpgradeResult{Path: old.Path, Action: Kept})
+			continue
+		}
+
+		removed = append(removed, old.Path)
+		report.Files = append(report.Files, UpgradeResult{Path: old.Path, Action: Deleted})
+	}
+
+	pending, err := listFiles(rootDir, PendingDir)
+	if err != nil {
+		return err
+	}
+	removed = append(removed, pending...)
+
+	if conflicts := report.Conflicts(); len(conflicts) != 0 {
+		// the merge base must stay as it was until conflicts are resolved.
+		paths := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			paths = append(paths, c.Path)
+		}
+		changes = append(changes, pendingFiles(mf, paths)...)
+	} else {
+		snapshots, err := listFiles(rootDir, BaseSnapshotDir)
+		if err != nil {
+			return err
+		}
+		removed = append(removed, snapshots...)
+		changes = append(changes, mf...)
+	}
+
+	return writeChanges(ctx, rootDir, changes, removed)
+}
+
+// upgradeFile merges the rendered file f into the project file, merged is nil if the project file is left as is.
+func upgradeFile(rootDir string, pm *ProjectManifest, f File, oldContent []byte, labels merge.Labels, rewrite rewriteFunc) (
+	res UpgradeResult, merged []byte, err error) {
+	res.Path = f.Path
+	recorded := pm.Checksum(f.Path)
+
+	current, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(f.Path)))
+	if os.IsNotExist(err) {
+		if recorded != "" {
+			res.Action = Skipped
+			return res, nil, nil
+		}
+		res.Action = Created
+		return res, f.Content, nil
+	}
+	if err != nil {
+		return res, nil, err
+	}
+
+	if bytes.Equal(current, f.Content) {
+		res.Action = Unchanged
+		return res, nil, nil
+	}
+	edited := recorded == "" || checksum(current) != recorded
+
+	base, ok, err := readBaseSnapshot(rootDir, f.Path)
+	if err != nil {
+		return res, nil, err
+	}
+	if !ok && recorded != "" && oldContent != nil && checksum(oldContent) == recorded {
+		base, ok = oldContent, true
+	}
+
+	rewritten := false
+	if rewrite != nil {
+		original := current
+		current = rewrite(f.Path, current)
+		rewritten = !bytes.Equal(current, original)
+		if ok {
+			base = rewrite(f.Path, base)
+		}
+	}
+
+	switch {
+	case ok && bytes.Equal(current, base), !ok && !edited:
+		res.Action, merged = Updated, f.Content
+	case ok && bytes.Equal(f.Content, base):
+		res.Action = Kept
+		if !rewritten {
+			return res, nil, nil
+		}
+		merged = current
+	case ok:
+		merged, res.Conflicts = merge.ThreeWay(base, current, f.Content, labels)
+		res.Action = Merged
+	default:
+		merged, res.Conflicts = merge.TwoWay(current, f.Content, labels)
+	}
+	if res.Conflicts != 0 {
+		res.Action = Conflict
+	}
+
+	return res, merged, nil
+}
+
+// writeChanges writes files into the project and removes removed files through the staging,
+// so either all changes are made or none. Cancellation of ctx rolls the changes back.
+func writeChanges(ctx context.Context, rootDir string, files []File, removed []string) (err error) {
+	st, err := newStaging(rootDir)
+	if err != nil {
+		return fmt.Errorf("create staging directory: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			st.rollback()
+			return
+		}
+		st.cleanup()
+	}()
+
+	written := make(map[string]bool, len(files))
+	for _, f := range files {
+		written[f.Path] = true
+	}
+	for _, p := range removed {
+		if !written[p] {
+			st.remove(p)
+		}
+	}
+	if err = writeFiles(st.dir, files); err != nil {
+		return err
+	}
+	if err = st.commit(ctx); err != nil {
+		return err
+	}
+
+	for _, p := range removed {
+		if !written[p] {
+			removeEmptyDirs(rootDir, filepath.Dir(filepath.Join(rootDir, filepath.FromSlash(p))))
+		}
+	}
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents up to rootDir while they are empty.
+func removeEmptyDirs(rootDir, dir string) {
+	rootDir = filepath.Clean(rootDir)
+	for dir != rootDir && len(dir) > len(rootDir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generateForUpgrade generates the project with go 1.20, upgrades change the go version, so go.mod,
+// Dockerfile and README get template changes.
+func generateForUpgrade(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	s := &Settings{ProjectName: "svc", ProjectRootDir: dir, GoVersion: "1.20", Logger: Zap, Database: NoDb, Router: GIN,
+		Reporter: discardReporter{}}
+	if _, err := Run(context.Background(), s); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	return dir
+}
+
+func upgradeActions(report *UpgradeReport) map[string]UpgradeAction {
+	actions := map[string]UpgradeAction{}
+	for _, f := range report.Files {
+		actions[f.Path] = f.Action
+	}
+	return actions
+}
+
+func TestUpgrade(t *testing.T) {
+	dir := generateForUpgrade(t)
+	dockerfile := readProjectFile(t, dir, "Dockerfile") + "# hand edit\n"
+	writeProjectFile(t, dir, "Dockerfile", dockerfile)
+
+	report, err := Upgrade(context.Background(), dir, &Manifest{GoVersion: "1.21"})
+	if err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	if conflicts := report.Conflicts(); len(conflicts) != 0 {
+		t.Fatalf("upgrade left conflicts: %v", conflicts)
+	}
+
+	actions := upgradeActions(report)
+	if actions["go.mod"] != Updated {
+		t.Errorf("go.mod is %s, want %s", actions["go.mod"], Updated)
+	}
+	if actions["Dockerfile"] != Merged {
+		t.Errorf("Dockerfile is %s, want %s", actions["Dockerfile"], Merged)
+	}
+	if actions[".gitignore"] != Unchanged {
+		t.Errorf(".gitignore is %s, want %s", actions[".gitignore"], Unchanged)
+	}
+
+	if goMod := readProjectFile(t, dir, "go.mod"); !strings.Contains(goMod, "\ngo 1.21\n") {
+		t.Errorf("go.mod isn't upgraded:\n%s", goMod)
+	}
+	dockerfile = readProjectFile(t, dir, "Dockerfile")
+	if !strings.Contains(dockerfile, "golang:1.21") || !strings.HasSuffix(dockerfile, "# hand edit\n") {
+		t.Errorf("Dockerfile doesn't have both template change and hand edit:\n%s", dockerfile)
+	}
+
+	pm, err := ReadProjectManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pm.Settings.GoVersion != "1.21" {
+		t.Errorf("stored go version = %s, want 1.21", pm.Settings.GoVersion)
+	}
+	if snapshot, _, _ := readBaseSnapshot(dir, "go.mod"); string(snapshot) != readProjectFile(t, dir, "go.mod") {
+		t.Error("snapshot of go.mod isn't upgraded")
+	}
+}
+
+func TestUpgradeConflict(t *testing.T) {
+	ctx := context.Background()
+	dir := generateForUpgrade(t)
+	goMod := strings.Replace(readProjectFile(t, dir, "go.mod"), "\ngo 1.20\n", "\ngo 1.20 // pinned by hand\n", 1)
+	writeProjectFile(t, dir, "go.mod", goMod)
+	manifest := readProjectFile(t, dir, ProjectManifestFile)
+	snapshot, _, _ := readBaseSnapshot(dir, "go.mod")
+
+	report, err := Upgrade(ctx, dir, &Manifest{GoVersion: "1.21"})
+	if err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	conflicts := report.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Path != "go.mod" {
+		t.Fatalf("conflicts = %v, want go.mod", conflicts)
+	}
+
+	want := "<<<<<<< current\ngo 1.20 // pinned by hand\n||||||| skeleton " + SkeletonVersion() + "\ngo 1.20\n" +
+		"=======\ngo 1.21\n>>>>>>> skeleton " + SkeletonVersion() + "\n"
+	if merged := readProjectFile(t, dir, "go.mod"); !strings.Contains(merged, want) {
+		t.Errorf("go.mod doesn't have diff3 conflict:\n%s\nwant:\n%s", merged, want)
+	}
+	if !strings.Contains(readProjectFile(t, dir, "Dockerfile"), "golang:1.21") {
+		t.Error("Dockerfile without conflicts isn't upgraded")
+	}
+
+	// .skeleton.yml and snapshots stay as they were, the new ones wait in the pending directory.
+	if readProjectFile(t, dir, ProjectManifestFile) != manifest {
+		t.Errorf("%s is changed while conflicts remain", ProjectManifestFile)
+	}
+	if current, _, _ := readBaseSnapshot(dir, "go.mod"); string(current) != string(snapshot) {
+		t.Error("snapshot of go.mod is changed while conflicts remain")
+	}
+	if pending := readProjectFile(t, dir, PendingDir+"/"+ProjectManifestFile); !strings.Contains(pending, "go_version: \"1.21\"") {
+		t.Errorf("pending %s doesn't have the new go version:\n%s", ProjectManifestFile, pending)
+	}
+	if pending := readProjectFile(t, dir, pendingConflictsFile); pending != "go.mod\n" {
+		t.Errorf("pending conflicts = %q, want go.mod", pending)
+	}
+	if pending := readProjectFile(t, dir, PendingDir+"/base/go.mod"); !strings.Contains(pending, "\ngo 1.21\n") {
+		t.Errorf("pending snapshot of go.mod isn't upgraded:\n%s", pending)
+	}
+}
+
+func TestUpgradeFinishPending(t *testing.T) {
+	ctx := context.Background()
+	dir := generateForUpgrade(t)
+	goMod := strings.Replace(readProjectFile(t, dir, "go.mod"), "\ngo 1.20\n", "\ngo 1.20 // pinned by hand\n", 1)
+	writeProjectFile(t, dir, "go.mod", goMod)
+	if _, err := Upgrade(ctx, dir, &Manifest{GoVersion: "1.21"}); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+
+	if _, err := Upgrade(ctx, dir, nil); err == nil || !strings.Contains(err.Error(), "go.mod") {
+		t.Fatalf("upgrade with conflict markers = %v, want error naming go.mod", err)
+	}
+
+	resolved := strings.Replace(goMod, "\ngo 1.20 // pinned by hand\n", "\ngo 1.21 // pinned by hand\n", 1)
+	writeProjectFile(t, dir, "go.mod", resolved)
+	report, err := Upgrade(ctx, dir, nil)
+	if err != nil {
+		t.Fatalf("upgrade after resolving: %v", err)
+	}
+	if conflicts := report.Conflicts(); len(conflicts) != 0 {
+		t.Fatalf("upgrade after resolving left conflicts: %v", conflicts)
+	}
+	if action := upgradeActions(report)["go.mod"]; action != Kept {
+		t.Errorf("resolved go.mod is %s, want %s", action, Kept)
+	}
+	if readProjectFile(t, dir, "go.mod") != resolved {
+		t.Error("resolved go.mod is changed")
+	}
+
+	pm, err := ReadProjectManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pm.Settings.GoVersion != "1.21" {
+		t.Errorf("stored go version = %s, want 1.21 of the pending upgrade", pm.Settings.GoVersion)
+	}
+	if snapshot, _, _ := readBaseSnapshot(dir, "go.mod"); !strings.Contains(string(snapshot), "\ngo 1.21\n") {
+		t.Errorf("snapshot of go.mod isn't taken from the pending upgrade:\n%s", snapshot)
+	}
+	if _, err = os.Stat(filepath.Join(dir, filepath.FromSlash(PendingDir))); !os.IsNotExist(err) {
+		t.Errorf("pending directory is left: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package generator
+
+import (
+	"context"
+	"strings"
+)
+
+// verifyCommands are run in the generated project by Verify.
+var verifyCommands = [][]string{
+	{"go", "build", "./..."},
+	{"go", "vet", "./..."},
+	{"go", "test", "-count=1", "./test/..."},
+}
+
+// Verify builds, vets and tests the project in rootDir, the commands and their output are reported to r.
+// It stops at the first failed command or when ctx is done.
+func Verify(ctx context.Context, rootDir string, r Reporter) error {
+	for _, args := range verifyCommands {
+		r.Step("verify: " + strings.Join(args, " "))
+		if err := runCommand(ctx, r, rootDir, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package generator
+
+import "runtime/debug"
+
+// Version of the generator, may be set at build time by
+// -ldflags "-X github.com/rtsoftSG/skeleton/internal/generator.Version=v1.0.0".
+var Version = ""
+
+// SkeletonVersion returns Version or module version from build info if Version is not set.
+func SkeletonVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"fmt"
+)
+
+// Require is the module required by go.mod of the generated project.
+type Require struct {
+	Path    string
+	Version string
+	// Indirect marks modules which aren't imported by the project, but pinned for reproducible builds,
+	// e.g. dependencies of jaeger-client-go which has no go.mod.
+	Indirect bool
+}
+
+// dependency is the module of the versions registry with the condition of its use.
+type dependency struct {
+	Require
+	// when reports whether the project imports the module, nil means always.
+	when func(d *templateData) bool
+	// goVersion is the go directive of the module's go.mod, empty if the module has no go.mod.
+	goVersion string
+}
+
+// dependencies is the versions registry: every module imported by templates with its pinned version.
+// go.mod of the project requires exactly the modules of chosen components, so go mod tidy and vendor
+// resolve the same versions on every run.
+var dependencies = []dependency{
+	{
+		Require:   Require{Path: "github.com/ClickHouse/clickhouse-go", Version: "v1.5.4"},
+		when:      func(d *templateData) bool { return d.UseClickhouse },
+		goVersion: "1.12",
+	},
+	{
+		Require:   Require{Path: "github.com/gin-gonic/gin", Version: "v1.9.1"},
+		when:      func(d *templateData) bool { return d.UseGin },
+		goVersion: "1.20",
+	},
+	{
+		Require:   Require{Path: "github.com/go-kit/kit", Version: "v0.12.0"},
+		when:      func(d *templateData) bool { return d.UseGoKitLogger || d.UseGorillaMux },
+		goVersion: "1.17",
+	},
+	{
+		Require:   Require{Path: "github.com/gorilla/mux", Version: "v1.8.0"},
+		when:      func(d *templateData) bool { return d.UseGorillaMux },
+		goVersion: "1.12",
+	},
+	{
+		Require:   Require{Path: "github.com/hashicorp/consul/api", Version: "v1.20.0"},
+		when:      func(d *templateData) bool { return d.UseConsul },
+		goVersion: "1.19",
+	},
+	{
+		Require:   Require{Path: "github.com/jackc/pgx/v4", Version: "v4.18.1"},
+		when:      func(d *templateData) bool { return d.UsePostgresql },
+		goVersion: "1.13",
+	},
+	{
+		Require:   Require{Path: "github.com/opentracing-contrib/go-gin", Version: "v0.0.0-20201220185307-1dd2273433a4"},
+		when:      func(d *templateData) bool { return d.UseGin && d.UseJaeger },
+		goVersion: "1.14",
+	},
+	{
+		Require:   Require{Path: "github.com/opentracing/opentracing-go", Version: "v1.2.0"},
+		when:      func(d *templateData) bool { return d.UseJaeger },
+		goVersion: "1.14",
+	},
+	{
+		Require: Require{Path: "github.com/pkg/errors", Version: "v0.9.1", Indirect: true},
+		when:    func(d *templateData) bool { return d.UseJaeger },
+	},
+	{
+		Require:   Require{Path: "github.com/prometheus/client_golang", Version: "v1.14.0"},
+		when:      func(d *templateData) bool { return d.UsePrometheus },
+		goVersion: "1.17",
+	},
+	{Require: Require{Path: "github.com/spf13/viper", Version: "v1.15.0"}, goVersion: "1.17"},
+	{Require: Require{Path: "github.com/stretchr/testify", Version: "v1.8.4"}, goVersion: "1.20"},
+	{
+		Require: Require{Path: "github.com/uber/jaeger-client-go", Version: "v2.30.0+incompatible"},
+		when:    func(d *templateData) bool { return d.UseJaeger },
+	},
+	{
+		Require: Require{Path: "github.com/uber/jaeger-lib", Version: "v2.4.1+incompatible", Indirect: true},
+		when:    func(d *templateData) bool { return d.UseJaeger },
+	},
+	{
+		Require:   Require{Path: "go.uber.org/atomic", Version: "v1.9.0", Indirect: true},
+		when:      func(d *templateData) bool { return d.UseJaeger },
+		goVersion: "1.13",
+	},
+	{
+		Require:   Require{Path: "go.uber.org/zap", Version: "v1.21.0"},
+		when:      func(d *templateData) bool { return d.UseZapLogger },
+		goVersion: "1.13",
+	},
+	{Require: Require{Path: "golang.org/x/sync", Version: "v0.3.0"}, goVersion: "1.17"},
+}
+
+// requires returns modules required by the project with data d, sorted by path.
+func requires(d *templateData) []Require {
+	var rs []Require
+	for _, dep := range dependencies {
+		if dep.when == nil || dep.when(d) {
+			rs = append(rs, dep.Require)
+		}
+	}
+	return rs
+}
+
+// MinGoVersion is the lowest go version of generated projects: the highest go directive of the pinned modules,
+// go refuses to build a module which requires newer go.
+var MinGoVersion = minGoVersion()
+
+func minGoVersion() string {
+	min := 0
+	for _, dep := range dependencies {
+		if minor, ok := parseGoVersion(dep.goVersion); ok && minor > min {
+			min = minor
+		}
+	}
+	return fmt.Sprintf("1.%d", min)
+}
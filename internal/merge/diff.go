@@ -0,0 +1,119 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// a and b are line indexes in the old and new text before the operation.
+	a, b int
+}
+
+// Unified returns unified diff of a and b with context lines around changes,
+// result is empty if texts are equal.
+func Unified(a, b []byte, nameA, nameB string, context int) []byte {
+	al, bl := splitLines(a), splitLines(b)
+	ops := editScript(al, bl)
+
+	var changes []int
+	for i, o := range ops {
+		if o.kind != opEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for len(changes) != 0 {
+		// hunk includes all changes separated by less than 2*context equal lines.
+		last := 0
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context+1 {
+			last++
+		}
+
+		from := max(changes[0]-context, 0)
+		to := min(changes[last]+context+1, len(ops))
+		changes = changes[last+1:]
+
+		aLen, bLen := 0, 0
+		for _, o := range ops[from:to] {
+			if o.kind != opInsert {
+				aLen++
+			}
+			if o.kind != opDelete {
+				bLen++
+			}
+		}
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(ops[from].a, aLen), hunkRange(ops[from].b, bLen))
+
+		for _, o := range ops[from:to] {
+			out.WriteByte(byte(o.kind))
+			out.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return out.Bytes()
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+func editScript(a, b []string) []op {
+	m := matches(a, b)
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && m[i] < 0:
+			ops = append(ops, op{kind: opDelete, line: a[i], a: i, b: j})
+			i++
+		case i < len(a) && m[i] == j:
+			ops = append(ops, op{kind: opEqual, line: a[i], a: i, b: j})
+			i++
+			j++
+		default:
+			ops = append(ops, op{kind: opInsert, line: b[j], a: i, b: j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,89 @@
+package merge
+
+import (
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name:    "equal",
+			a:       "a\nb\n",
+			b:       "a\nb\n",
+			context: 3,
+			want:    "",
+		},
+		{
+			name:    "changed line",
+			a:       "a\nb\nc\n",
+			b:       "a\nB\nc\n",
+			context: 3,
+			want:    "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:    "context is limited",
+			a:       "1\n2\n3\n4\n5\n6\n7\n",
+			b:       "1\n2\n3\nX\n5\n6\n7\n",
+			context: 1,
+			want:    "--- a/f\n+++ b/f\n@@ -3,3 +3,3 @@\n 3\n-4\n+X\n 5\n",
+		},
+		{
+			name:    "distant changes are separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "X\n2\n3\n4\n5\n6\n7\nY\n",
+			context: 1,
+			want: "--- a/f\n+++ b/f\n" +
+				"@@ -1,2 +1,2 @@\n-1\n+X\n 2\n" +
+				"@@ -7,2 +7,2 @@\n 7\n-8\n+Y\n",
+		},
+		{
+			name:    "close changes share the hunk",
+			a:       "1\n2\n3\n4\n5\n",
+			b:       "X\n2\n3\n4\nY\n",
+			context: 2,
+			want:    "--- a/f\n+++ b/f\n@@ -1,5 +1,5 @@\n-1\n+X\n 2\n 3\n 4\n-5\n+Y\n",
+		},
+		{
+			name:    "insertion",
+			a:       "a\nc\n",
+			b:       "a\nb\nc\n",
+			context: 3,
+			want:    "--- a/f\n+++ b/f\n@@ -1,2 +1,3 @@\n a\n+b\n c\n",
+		},
+		{
+			name:    "new file",
+			a:       "",
+			b:       "a\nb\n",
+			context: 3,
+			want:    "--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "deleted file",
+			a:       "a\nb\n",
+			b:       "",
+			context: 3,
+			want:    "--- a/f\n+++ b/f\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "missing trailing newline",
+			a:       "a\nb",
+			b:       "a\nb\n",
+			context: 3,
+			want:    "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified([]byte(tt.a), []byte(tt.b), "a/f", "b/f", tt.context)
+			if string(got) != tt.want {
+				t.Errorf("diff:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// Package merge implements line based diff and merge of text files with git style conflict markers.
+package merge
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Labels are names of the merged versions written after conflict markers.
+type Labels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// ThreeWay merges changes made in ours and theirs relative to base.
+// Hunks changed on both sides in different ways are wrapped in diff3 style conflict markers,
+// conflicts is the number of such hunks.
+func ThreeWay(base, ours, theirs []byte, l Labels) (result []byte, conflicts int) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	mo, mt := matches(b, o), matches(b, t)
+
+	out := &bytes.Buffer{}
+	i, j, k := 0, 0, 0
+	for {
+		// next base line which is kept on both sides.
+		m := i
+		for m < len(b) && (mo[m] < 0 || mt[m] < 0) {
+			m++
+		}
+
+		oEnd, tEnd := len(o), len(t)
+		if m < len(b) {
+			oEnd, tEnd = mo[m], mt[m]
+		}
+
+		bc, oc, tc := b[i:m], o[j:oEnd], t[k:tEnd]
+		switch {
+		case equal(oc, bc):
+			writeLines(out, tc)
+		case equal(tc, bc), equal(oc, tc):
+			writeLines(out, oc)
+		default:
+			if merged, ok := mergeInsertion(oc, bc, tc); ok {
+				writeLines(out, merged)
+				break
+			}
+			conflicts++
+			writeConflict(out, oc, bc, tc, l, true)
+		}
+
+		if m == len(b) {
+			break
+		}
+		out.WriteString(b[m])
+		i, j, k = m+1, oEnd+1, tEnd+1
+	}
+
+	return out.Bytes(), conflicts
+}
+
+// TwoWay merges ours and theirs without common ancestor, every differing hunk is a conflict.
+func TwoWay(ours, theirs []byte, l Labels) (result []byte, conflicts int) {
+	o, t := splitLines(ours), splitLines(theirs)
+	mt := matches(o, t)
+
+	out := &bytes.Buffer{}
+	j, k := 0, 0
+	for {
+		m := j
+		for m < len(o) && mt[m] < 0 {
+			m++
+		}
+
+		tEnd := len(t)
+		if m < len(o) {
+			tEnd = mt[m]
+		}
+
+		if oc, tc := o[j:m], t[k:tEnd]; len(oc) != 0 || len(tc) != 0 {
+			conflicts++
+			writeConflict(out, oc, nil, tc, l, false)
+		}
+
+		if m == len(o) {
+			break
+		}
+		out.WriteString(o[m])
+		j, k = m+1, tEnd+1
+	}
+
+	return out.Bytes(), conflicts
+}
+
+// HasConflicts reports whether text has conflict markers written by ThreeWay or TwoWay.
+func HasConflicts(text []byte) bool {
+	start, end := false, false
+	for _, line := range splitLines(text) {
+		switch {
+		case isMarker(line, "<<<<<<<"):
+			start = true
+		case isMarker(line, ">>>>>>>"):
+			end = start
+		}
+	}
+	return end
+}
+
+func isMarker(line, m string) bool {
+	rest := strings.TrimPrefix(line, m)
+	return len(rest) != len(line) && (rest == "" || rest[0] == ' ' || rest[0] == '\n')
+}
+
+// mergeInsertion resolves hunks where one side only adds lines right before or after the unchanged base lines
+// and the other side changes those lines: the added lines are kept next to the other side's version.
+func mergeInsertion(ours, base, theirs []string) ([]string, bool) {
+	if merged, ok := insertAround(ours, base, theirs); ok {
+		return merged, true
+	}
+	return insertAround(theirs, base, ours)
+}
+
+func insertAround(inserted, base, other []string) ([]string, bool) {
+	n := len(inserted) - len(base)
+	if len(base) == 0 || n <= 0 {
+		return nil, false
+	}
+
+	switch {
+	case equal(inserted[n:], base):
+		return append(append([]string{}, inserted[:n]...), other...), true
+	case equal(inserted[:len(base)], base):
+		return append(append([]string{}, other...), inserted[len(base):]...), true
+	default:
+		return nil, false
+	}
+}
+
+func writeConflict(out *bytes.Buffer, ours, base, theirs []string, l Labels, withBase bool) {
+	// lines equal on both sides are not a part of the conflict.
+	p := 0
+	for p < len(ours) && p < len(theirs) && ours[p] == theirs[p] {
+		p++
+	}
+	writeLines(out, ours[:p])
+	ours, theirs = ours[p:], theirs[p:]
+
+	s := 0
+	for s < len(ours) && s < len(theirs) && ours[len(ours)-1-s] == theirs[len(theirs)-1-s] {
+		s++
+	}
+	suffix := ours[len(ours)-s:]
+	ours, theirs = ours[:len(ours)-s], theirs[:len(theirs)-s]
+
+	marker(out, "<<<<<<<", l.Ours)
+	writeTerminatedLines(out, ours)
+	if withBase {
+		marker(out, "|||||||", l.Base)
+		writeTerminatedLines(out, base)
+	}
+	marker(out, "=======", "")
+	writeTerminatedLines(out, theirs)
+	marker(out, ">>>>>>>", l.Theirs)
+
+	writeLines(out, suffix)
+}
+
+func marker(out *bytes.Buffer, m, label string) {
+	out.WriteString(m)
+	if label != "" {
+		out.WriteString(" " + label)
+	}
+	out.WriteString("\n")
+}
+
+func writeLines(out *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+// writeTerminatedLines writes lines adding line break to the last line if it's missing,
+// so conflict marker always starts from the new line.
+func writeTerminatedLines(out *bytes.Buffer, lines []string) {
+	writeLines(out, lines)
+	if len(lines) != 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		out.WriteString("\n")
+	}
+}
+
+// splitLines splits text into lines, each line keeps its line break.
+func splitLines(text []byte) []string {
+	var lines []string
+	for len(text) != 0 {
+		n := bytes.IndexByte(text, '\n') + 1
+		if n == 0 {
+			n = len(text)
+		}
+		lines = append(lines, string(text[:n]))
+		text = text[n:]
+	}
+	return lines
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns for every line of a index of the matched line of b or -1,
+// matched lines form the longest common subsequence.
+func matches(a, b []string) []int {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+
+	// common prefix and suffix don't need the table.
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		m[p] = p
+		p++
+	}
+	s := 0
+	for s < len(a)-p && s < len(b)-p && a[len(a)-1-s] == b[len(b)-1-s] {
+		m[len(a)-1-s] = len(b) - 1 - s
+		s++
+	}
+
+	ra, rb := a[p:len(a)-s], b[p:len(b)-s]
+	if len(ra) == 0 || len(rb) == 0 {
+		return m
+	}
+
+	// lcs[x][y] is the length of the LCS of ra[x:] and rb[y:].
+	lcs := make([][]int32, len(ra)+1)
+	for x := range lcs {
+		lcs[x] = make([]int32, len(rb)+1)
+	}
+	for x := len(ra) - 1; x >= 0; x-- {
+		for y := len(rb) - 1; y >= 0; y-- {
+			switch {
+			case ra[x] == rb[y]:
+				lcs[x][y] = lcs[x+1][y+1] + 1
+			case lcs[x+1][y] >= lcs[x][y+1]:
+				lcs[x][y] = lcs[x+1][y]
+			default:
+				lcs[x][y] = lcs[x][y+1]
+			}
+		}
+	}
+
+	for x, y := 0, 0; x < len(ra) && y < len(rb); {
+		switch {
+		case ra[x] == rb[y]:
+			m[p+x] = p + y
+			x++
+			y++
+		case lcs[x+1][y] >= lcs[x][y+1]:
+			x++
+		default:
+			y++
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,249 @@
+package merge
+
+import (
+	"testing"
+)
+
+var labels = Labels{Ours: "current", Base: "base", Theirs: "new"}
+
+func TestThreeWay(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		wantConflicts      int
+	}{
+		{
+			name:   "no changes",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "only theirs changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "only ours changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nC\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "changes in different lines",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nX\nc\n",
+			theirs: "a\nX\nc\n",
+			want:   "a\nX\nc\n",
+		},
+		{
+			name:   "insertions and deletions in different places",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "a\nnew\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\ne\n",
+			want:   "a\nnew\nb\nc\ne\n",
+		},
+		{
+			name:   "overlapping edits",
+			base:   "a\nb\nc\n",
+			ours:   "a\nours\nc\n",
+			theirs: "a\ntheirs\nc\n",
+			want: "a\n" +
+				"<<<<<<< current\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> new\n" +
+				"c\n",
+			wantConflicts: 1,
+		},
+		{
+			name:   "equal lines at conflict edges are kept out of markers",
+			base:   "a\nb\n",
+			ours:   "a\nx\nours\ny\n",
+			theirs: "a\nx\ntheirs\ny\n",
+			want: "a\nx\n" +
+				"<<<<<<< current\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> new\n" +
+				"y\n",
+			wantConflicts: 1,
+		},
+		{
+			name:   "two conflicts",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "1\nb\nc\nd\n1\n",
+			theirs: "2\nb\nc\nd\n2\n",
+			want: "<<<<<<< current\n1\n||||||| base\na\n=======\n2\n>>>>>>> new\n" +
+				"b\nc\nd\n" +
+				"<<<<<<< current\n1\n||||||| base\ne\n=======\n2\n>>>>>>> new\n",
+			wantConflicts: 2,
+		},
+		{
+			name:   "insertion before changed line",
+			base:   "a\nb\nc\n",
+			ours:   "a\ninserted\nb\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\ninserted\nB\nc\n",
+		},
+		{
+			name:   "insertion after changed line",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nb\ninserted\nc\n",
+			want:   "a\nB\ninserted\nc\n",
+		},
+		{
+			name:   "missing trailing newline changed by theirs",
+			base:   "a\nb",
+			ours:   "a\nb",
+			theirs: "a\nb\nc",
+			want:   "a\nb\nc",
+		},
+		{
+			name:   "missing trailing newline added by ours",
+			base:   "a\nm\nb",
+			ours:   "a\nm\nb\n",
+			theirs: "A\nm\nb",
+			want:   "A\nm\nb\n",
+		},
+		{
+			name:   "conflict on the last line without newline",
+			base:   "a\nb",
+			ours:   "a\nours",
+			theirs: "a\ntheirs",
+			want: "a\n" +
+				"<<<<<<< current\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> new\n",
+			wantConflicts: 1,
+		},
+		{
+			name:   "file deleted by theirs",
+			base:   "a\nb\n",
+			ours:   "a\nb\n",
+			theirs: "",
+			want:   "",
+		},
+		{
+			name:   "file deleted by ours",
+			base:   "a\nb\n",
+			ours:   "",
+			theirs: "a\nb\n",
+			want:   "",
+		},
+		{
+			name:          "file deleted by theirs and edited by ours",
+			base:          "a\nb\n",
+			ours:          "a\nB\n",
+			theirs:        "",
+			want:          "<<<<<<< current\na\nB\n||||||| base\na\nb\n=======\n>>>>>>> new\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "file created on both sides",
+			base:          "",
+			ours:          "a\n",
+			theirs:        "b\n",
+			want:          "<<<<<<< current\na\n||||||| base\n=======\nb\n>>>>>>> new\n",
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := ThreeWay([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), labels)
+			if string(got) != tt.want {
+				t.Errorf("result:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("conflicts = %d, want %d", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestTwoWay(t *testing.T) {
+	tests := []struct {
+		name          string
+		ours, theirs  string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "equal",
+			ours:   "a\nb\n",
+			theirs: "a\nb\n",
+			want:   "a\nb\n",
+		},
+		{
+			name:          "changed line",
+			ours:          "a\nb\nc\n",
+			theirs:        "a\nB\nc\n",
+			want:          "a\n<<<<<<< current\nb\n=======\nB\n>>>>>>> new\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "inserted line",
+			ours:          "a\nc\n",
+			theirs:        "a\nb\nc\n",
+			want:          "a\n<<<<<<< current\n=======\nb\n>>>>>>> new\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "missing trailing newline",
+			ours:          "a\nb",
+			theirs:        "a\nb\n",
+			want:          "a\n<<<<<<< current\nb\n=======\nb\n>>>>>>> new\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "deleted file",
+			ours:          "a\n",
+			theirs:        "",
+			want:          "<<<<<<< current\na\n=======\n>>>>>>> new\n",
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := TwoWay([]byte(tt.ours), []byte(tt.theirs), labels)
+			if string(got) != tt.want {
+				t.Errorf("result:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("conflicts = %d, want %d", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestHasConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "empty", text: "", want: false},
+		{name: "plain text", text: "a\nb\n", want: false},
+		{name: "three-way conflict", text: "<<<<<<< current\na\n||||||| base\n=======\nb\n>>>>>>> new\n", want: true},
+		{name: "markers without labels", text: "<<<<<<<\na\n=======\nb\n>>>>>>>", want: true},
+		{name: "only start marker", text: "<<<<<<< current\na\n", want: false},
+		{name: "end before start", text: ">>>>>>> new\n<<<<<<< current\n", want: false},
+		{name: "marker inside line", text: "x <<<<<<< y\nx >>>>>>> y\n", want: false},
+		{name: "longer run of angle brackets", text: "<<<<<<<<\n>>>>>>>>\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasConflicts([]byte(tt.text)); got != tt.want {
+				t.Errorf("HasConflicts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Package server is the HTTP API and web form of the generator: it lists available choices
+// and streams generated projects as zip archives.
+package server
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+)
+
+//go:embed form.html
+var formHTML string
+
+// maxRequestSize limits the settings payload.
+const maxRequestSize = 64 << 10
+
+// Server handles requests of the generator service.
+type Server struct {
+	goVersion string
+	form      *template.Template
+	mux       *http.ServeMux
+}
+
+// New returns server rendering projects with goVersion unless the request sets another one.
+func New(goVersion string) *Server {
+	s := &Server{
+		goVersion: goVersion,
+		form:      template.Must(template.New("form").Parse(formHTML)),
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/", s.handleForm)
+	s.mux.HandleFunc("/api/options", s.handleOptions)
+	s.mux.HandleFunc("/api/generate", s.handleGenerate)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Choice is a value of the settings field.
+type Choice struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Default bool   `json:"default,omitempty"`
+}
+
+// Integration is an optional part of the project switched on by the boolean settings field.
+type Integration struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Requires string `json:"requires,omitempty"`
+}
+
+// Options lists everything the project may be generated with, IDs are field names and values of the settings payload.
+type Options struct {
+	GoVersion    string        `json:"go_version"`
+	Loggers      []Choice      `json:"logger"`
+	Databases    []Choice      `json:"db"`
+	Routers      []Choice      `json:"router"`
+	Integrations []Integration `json:"integrations"`
+}
+
+func (s *Server) options() *Options {
+	o := &Options{
+		GoVersion: s.goVersion,
+		Integrations: []Integration{
+			{ID: "consul", Title: "Register service in consul"},
+			{ID: "consul_sync_config", Title: "Sync config with consul", Requires: "consul"},
+			{ID: "jaeger", Title: "Jaeger tracer"},
+			{ID: "prometheus", Title: "Prometheus metrics"},
+		},
+	}
+	for i, v := range generator.Loggers {
+		o.Loggers = append(o.Loggers, Choice{ID: string(v), Title: v.Title(), Default: i == 0})
+	}
+	for i, v := range generator.Databases {
+		o.Databases = append(o.Databases, Choice{ID: string(v), Title: v.Title(), Default: i == 0})
+	}
+	for i, v := range generator.Routers {
+		o.Routers = append(o.Routers, Choice{ID: string(v), Title: v.Title(), Default: i == 0})
+	}
+	return o
+}
+
+func (s *Server) handleForm(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := s.form.Execute(&buf, s.options()); err != nil {
+		log.Printf("render form: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (s *Server) handleOptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.options())
+}
+
+// handleGenerate accepts settings as JSON manifest or as the form and responds with zip of the project.
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+
+	var m *generator.Manifest
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		m, err = generator.LoadManifest(r.Body)
+	} else {
+		m, err = manifestFromForm(r)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	settings, err := s.settings(m)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// every request renders its own project in memory, nothing is shared between requests.
+	files, err := generator.RenderProject(settings)
+	if err != nil {
+		log.Printf("render %s: %v", settings.ProjectName, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = generator.WriteZip(&buf, settings.ProjectName, files); err != nil {
+		log.Printf("zip %s: %v", settings.ProjectName, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", settings.ProjectName+".zip"))
+	w.Header().Set("Content-Length", fmt.Sprint(buf.Len()))
+	_, _ = buf.WriteTo(w)
+}
+
+// settings applies the manifest over default choices. Templates directories and packs are files of the server,
+// so requests may not use them.
+func (s *Server) settings(m *generator.Manifest) (*generator.Settings, error) {
+	if m.TemplatesDir != "" {
+		return nil, &generator.FieldError{Field: "templates_dir", Reason: "is not allowed"}
+	}
+	if len(m.Packs) != 0 || len(m.PackOptions) != 0 {
+		return nil, &generator.FieldError{Field: "packs", Reason: "are not allowed"}
+	}
+
+	settings := &generator.Settings{
+		GoVersion: s.goVersion,
+		Logger:    generator.Loggers[0],
+		Database:  generator.Databases[0],
+		Router:    generator.Routers[0],
+	}
+	m.Apply(settings)
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// manifestFromForm reads the form fields named as manifest fields, checkboxes are on when present.
+func manifestFromForm(r *http.Request) (*generator.Manifest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	m := &generator.Manifest{
+		Name:      strings.TrimSpace(r.PostForm.Get("name")),
+		Module:    strings.TrimSpace(r.PostForm.Get("module")),
+		GoVersion: strings.TrimSpace(r.PostForm.Get("go_version")),
+		Logger:    generator.LoggerChoice(r.PostForm.Get("logger")),
+		Database:  generator.DBChoice(r.PostForm.Get("db")),
+		Router:    generator.RouterChoice(r.PostForm.Get("router")),
+	}
+	checkbox := func(name string) *bool {
+		v := r.PostForm.Get(name) != ""
+		return &v
+	}
+	m.UseConsul = checkbox("consul")
+	m.SyncConfigWithConsul = checkbox("consul_sync_config")
+	m.UseJaeger = checkbox("jaeger")
+	m.UsePrometheus = checkbox("prometheus")
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+// writeError responds with 400 and the invalid field if the error is about the settings field.
+func writeError(w http.ResponseWriter, err error) {
+	res := errorResponse{Error: err.Error()}
+	var fieldErr *generator.FieldError
+	if errors.As(err, &fieldErr) {
+		res.Field = fieldErr.Field
+	}
+	writeJSON(w, http.StatusBadRequest, res)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+}
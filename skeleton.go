@@ -0,0 +1,189 @@
+// Package skeleton is the library API of the A-PLATFORM microservice generator.
+// It renders projects in memory and writes them into any FS, so other tools may embed the generator
+// and check its output without touching disk:
+//
+//	files, err := skeleton.Render(&skeleton.Settings{
+//		ProjectName: "orders",
+//		ModulePath:  "gitlab.company.local/platform/orders",
+//		Logger:      skeleton.Zap,
+//		Database:    skeleton.Postgresql,
+//		Router:      skeleton.GIN,
+//	})
+//
+// Downloading dependencies needs the go toolchain and the project on disk, so it's a separate Vendor step.
+package skeleton
+
+import (
+	"context"
+	"fmt"
+	"github.com/rtsoftSG/skeleton/internal/generator"
+	"io/fs"
+	"path"
+)
+
+// Settings are choices the project is generated with.
+type Settings struct {
+	// ProjectName is the name of cmd/<name> directory, binary and service.
+	ProjectName string
+	// ModulePath is the go module path, project name is used if it's empty.
+	ModulePath string
+	// GoVersion is the go version of the project, version of the go toolchain in PATH is used if it's empty.
+	GoVersion            string
+	Logger               LoggerChoice
+	Database             DBChoice
+	Router               RouterChoice
+	UseConsul            bool
+	SyncConfigWithConsul bool
+	UseJaeger            bool
+	UsePrometheus        bool
+	// TemplatesDir has templates which override embedded assets with the same name.
+	TemplatesDir string
+	// Packs are directories of template packs, PackOptions are answers to their questions.
+	Packs       []string
+	PackOptions map[string]string
+}
+
+// Validate checks that all choices are set and consistent.
+func (s *Settings) Validate() error {
+	return s.generator().Validate()
+}
+
+// Apply copies fields of m which are set into s.
+func (s *Settings) Apply(m *Manifest) {
+	gs := s.generator()
+	m.Apply(gs)
+	*s = Settings{
+		ProjectName:          gs.ProjectName,
+		ModulePath:           gs.ModulePath,
+		GoVersion:            gs.GoVersion,
+		Logger:               gs.Logger,
+		Database:             gs.Database,
+		Router:               gs.Router,
+		UseConsul:            gs.UseConsul,
+		SyncConfigWithConsul: gs.SyncConfigWithConsul,
+		UseJaeger:            gs.UseJaeger,
+		UsePrometheus:        gs.UsePrometheus,
+		TemplatesDir:         gs.TemplatesDir,
+		Packs:                gs.Packs,
+		PackOptions:          gs.PackOptions,
+	}
+}
+
+// generator returns settings of the generator.
+func (s *Settings) generator() *generator.Settings {
+	return &generator.Settings{
+		ProjectName:          s.ProjectName,
+		ModulePath:           s.ModulePath,
+		GoVersion:            s.GoVersion,
+		Logger:               s.Logger,
+		Database:             s.Database,
+		Router:               s.Router,
+		UseConsul:            s.UseConsul,
+		SyncConfigWithConsul: s.SyncConfigWithConsul,
+		UseJaeger:            s.UseJaeger,
+		UsePrometheus:        s.UsePrometheus,
+		TemplatesDir:         s.TemplatesDir,
+		Packs:                s.Packs,
+		PackOptions:          s.PackOptions,
+	}
+}
+
+// FieldError describes invalid value of the settings field.
+type FieldError = generator.FieldError
+
+// CommandError is returned by Vendor when go mod tidy or go mod vendor fails.
+type CommandError = generator.CommandError
+
+// Manifest is serialisable form of Settings used in settings files, Settings.Apply sets its choices.
+type Manifest = generator.Manifest
+
+type (
+	LoggerChoice = generator.LoggerChoice
+	DBChoice     = generator.DBChoice
+	RouterChoice = generator.RouterChoice
+)
+
+const (
+	GoKit = generator.GoKit
+	Zap   = generator.Zap
+
+	NoDb       = generator.NoDb
+	Clickhouse = generator.Clickhouse
+	Postgresql = generator.Postgresql
+
+	GorillaMux = generator.GorillaMux
+	GIN        = generator.GIN
+)
+
+// ProjectManifestFile is the name of the file in the project root which describes how the project was generated.
+const ProjectManifestFile = generator.ProjectManifestFile
+
+var (
+	// LoadManifest reads manifest in YAML or JSON format.
+	LoadManifest = generator.LoadManifest
+	// DetectGoVersion returns version of the go toolchain found in PATH.
+	DetectGoVersion = generator.DetectGoVersion
+	// Version returns the generator version.
+	Version = generator.SkeletonVersion
+)
+
+// NewManifest returns manifest with all fields taken from settings.
+func NewManifest(settings *Settings) *Manifest {
+	return generator.NewManifest(settings.generator())
+}
+
+// Render validates settings and renders the project in memory, keys of the map are slash separated paths
+// relative to the project root. The result includes .skeleton.yml and snapshots used by upgrade,
+// it's everything generate writes except vendored dependencies. The go version defaults to the version
+// of the go toolchain in PATH as in generate.
+func Render(settings *Settings) (map[string][]byte, error) {
+	s := settings.generator()
+	if s.GoVersion == "" {
+		s.GoVersion = generator.DetectGoVersion()
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	files, err := generator.RenderProject(s)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]byte, len(files))
+	for _, f := range files {
+		res[f.Path] = f.Content
+	}
+	return res, nil
+}
+
+// Write renders the project and writes it into target.
+func Write(settings *Settings, target FS) error {
+	files, err := Render(settings)
+	if err != nil {
+		return err
+	}
+
+	for p, content := range files {
+		if err = target.MkdirAll(path.Dir(p), 0755); err != nil {
+			return fmt.Errorf("create %s: %w", path.Dir(p), err)
+		}
+		if err = target.WriteFile(p, content, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Vendor runs go mod tidy and go mod vendor in the project written to rootDir on disk,
+// a failed command is returned as *CommandError with its exit status and stderr.
+// The commands are killed when ctx is done.
+func Vendor(ctx context.Context, rootDir string) error {
+	return generator.Vendor(ctx, rootDir)
+}
+
+// FS is the target file system the project is written to, paths are slash separated and relative to the project root.
+type FS interface {
+	MkdirAll(path string, perm fs.FileMode) error
+	WriteFile(path string, data []byte, perm fs.FileMode) error
+}